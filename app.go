@@ -1,23 +1,35 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"lag-monitor/internal/config"
 	"lag-monitor/internal/domain"
+	"lag-monitor/internal/infra/report"
+	"lag-monitor/internal/infra/transfer"
 	"lag-monitor/internal/usecase"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 )
 
+// Tempo máximo para os jobs pararem no encerramento antes de fechar o banco
+const shutdownTimeout = 5 * time.Second
+
 type App struct {
 	ctx     context.Context
 	service *usecase.MonitorService
 	repo    domain.Repository
 	cfg     *config.ConfigManager
+
+	shutdownOnce sync.Once
 }
 
 func NewApp(svc *usecase.MonitorService, r domain.Repository, cfg *config.ConfigManager) *App {
@@ -31,15 +43,41 @@ func NewApp(svc *usecase.MonitorService, r domain.Repository, cfg *config.Config
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
-	a.service.StartRetentionPolicy()
+	// A configuração já foi carregada no main (o armazenamento depende dela)
+	for _, target := range a.cfg.Data.Targets {
+		a.service.AddHost(target)
+	}
 
-	if err := a.cfg.Load(); err != nil {
-		fmt.Println("Erro ao carregar config:", err)
+	a.service.SetAlertRules(a.cfg.Data.AlertRules)
+	if err := usecase.ValidateSLAPolicy(a.cfg.Data.SLA); err != nil {
+		fmt.Println("Política de SLA ignorada:", err)
+	} else {
+		a.service.SetSLAPolicy(a.cfg.Data.SLA)
 	}
+	a.service.SetRetention(a.cfg.Data.Retention())
+	a.service.SetBackupPolicy(a.cfg.Data.Backup)
 
-	for _, target := range a.cfg.Data.Targets {
-		a.service.AddHost(target)
+	if err := a.service.SetMaintenanceWindows(a.cfg.Data.Maintenance.Windows); err != nil {
+		fmt.Println("Janelas de manutenção ignoradas:", err)
 	}
+
+	a.service.Start(ctx)
+}
+
+// shutdown para os jobs e só então fecha o repositório, gravando o que restou no
+// buffer. Pode ser chamado mais de uma vez (OnShutdown, sinal e fim do wails.Run).
+func (a *App) shutdown(ctx context.Context) {
+	a.shutdownOnce.Do(func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := a.service.Stop(stopCtx); err != nil {
+			fmt.Println("Jobs não pararam a tempo:", err)
+		}
+		if err := a.repo.Close(); err != nil {
+			fmt.Println("Erro ao fechar o banco:", err)
+		}
+	})
 }
 
 // --- NOVOS MÉTODOS PARA PERSISTÊNCIA NO SETTINGS.JSON ---
@@ -51,8 +89,29 @@ func (a *App) GetConfig() config.AppConfig {
 
 // UpdateConfig recebe a configuração do frontend e salva no settings.json
 func (a *App) UpdateConfig(newCfg config.AppConfig) error {
-	a.cfg.UpdateConfig(newCfg)
-	return a.cfg.Save()
+	if err := usecase.ValidateSLAPolicy(newCfg.SLA); err != nil {
+		return err
+	}
+	changes := a.cfg.UpdateConfig(newCfg)
+	a.service.SetAlertRules(newCfg.AlertRules)
+	a.service.SetSLAPolicy(newCfg.SLA)
+	a.service.SetRetention(newCfg.Retention())
+	a.service.SetBackupPolicy(newCfg.Backup)
+	for _, t := range newCfg.Targets {
+		a.service.SetHostRetention(t.ID, t.RetentionDays)
+		a.service.SetHostTags(t.ID, t.Tags)
+	}
+	if err := a.service.SetMaintenanceWindows(newCfg.Maintenance.Windows); err != nil {
+		fmt.Println("Janelas de manutenção ignoradas:", err)
+	}
+	if err := a.cfg.Save(); err != nil {
+		return err
+	}
+	a.service.AnnotateConfigChanges("", changes)
+
+	// A nova retenção vale imediatamente, sem esperar o próximo ciclo
+	a.service.TriggerCleanup()
+	return nil
 }
 
 // --- MÉTODOS JS EXISTENTES ---
@@ -64,12 +123,14 @@ func (a *App) AddTarget(ip string, name string) domain.Host {
 
 	a.service.AddHost(host)
 	a.cfg.AddTarget(host)
+	a.service.AnnotateConfigChanges(host.ID, []string{fmt.Sprintf("alvo %s adicionado (%s)", host.ID, ip)})
 	return host
 }
 
 func (a *App) RemoveTarget(hostID string) {
 	a.service.RemoveHost(hostID)
 	a.cfg.RemoveTarget(hostID)
+	a.service.AnnotateConfigChanges(hostID, []string{fmt.Sprintf("alvo %s removido", hostID)})
 }
 
 func (a *App) GetTargets() []domain.Host {
@@ -79,28 +140,185 @@ func (a *App) GetTargets() []domain.Host {
 func (a *App) SetTargetActive(hostID string, active bool) {
 	a.service.ToggleHostStatus(hostID, active)
 	a.cfg.UpdateTargetStatus(hostID, active)
+
+	state := "retomado"
+	if !active {
+		state = "pausado"
+	}
+	a.service.AnnotateConfigChanges(hostID, []string{"monitoramento " + state})
 }
 
-func (a *App) GetReport(hostID string, startStr, endStr string) (string, error) {
+// parseRange converte o período no formato do <input type="datetime-local">, no fuso
+// local (o mesmo relógio que o usuário vê na tela)
+func parseRange(startStr, endStr string) (time.Time, time.Time, error) {
 	layout := "2006-01-02T15:04"
-	start, _ := time.Parse(layout, startStr)
-	end, _ := time.Parse(layout, endStr)
+	start, err := time.ParseInLocation(layout, startStr, time.Local)
+	if err != nil {
+		return start, start, fmt.Errorf("data inicial inválida: %q", startStr)
+	}
+	end, err := time.ParseInLocation(layout, endStr, time.Local)
+	if err != nil {
+		return start, end, fmt.Errorf("data final inválida: %q", endStr)
+	}
+	return start, end, nil
+}
+
+// GetReport gera o relatório usando as preferências do settings.json. Com um host grava
+// o resumo em texto e os dados técnicos; com vários, o comparativo entre eles em HTML.
+func (a *App) GetReport(hostIDs []string, startStr, endStr string) (string, error) {
+	opts := a.reportOptions()
+	switch len(hostIDs) {
+	case 0:
+		return "", fmt.Errorf("nenhum host selecionado")
+	case 1:
+		return a.GetReportWithOptions(hostIDs[0], startStr, endStr, opts)
+	}
 
-	summary, raw, err := a.service.GenerateDualReport(hostID, start, end)
+	start, end, err := parseRange(startStr, endStr)
 	if err != nil {
 		return "", err
 	}
+	cmp, err := a.service.BuildComparison(hostIDs, start, end, opts)
+	if err != nil {
+		return "", err
+	}
+	return exportFile("COMPARATIVO", hostIDs, ".html", func(w io.Writer) error {
+		return report.ComparisonHTML(w, cmp)
+	})
+}
 
-	home, _ := os.UserHomeDir()
-	timestamp := time.Now().Unix()
+func (a *App) GetReportWithOptions(hostID string, startStr, endStr string, opts usecase.ReportOptions) (string, error) {
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := a.service.BuildReport(hostID, start, end, opts)
+	if err != nil {
+		return "", err
+	}
+	summaryPath, err := exportFile("RESUMO", []string{hostID}, ".txt", func(w io.Writer) error {
+		return report.Text(w, data)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Dados técnicos: uma linha por ping ou, em períodos longos, por intervalo agregado
+	_, err = exportFile("DADOS-TECNICOS", []string{hostID}, ".csv", func(w io.Writer) error {
+		if data.Resolution == domain.ResolutionRaw {
+			enc := transfer.NewEncoder(w, transfer.FormatReport)
+			if _, err := a.service.ExportHistory([]string{hostID}, start, end, enc.Write); err != nil {
+				return err
+			}
+			return enc.Flush()
+		}
+		enc := report.NewRollupCSV(w)
+		if _, err := a.service.ExportRollups([]string{hostID}, data.Resolution, start, end, enc.Write); err != nil {
+			return err
+		}
+		return enc.Flush()
+	})
+	return summaryPath, err
+}
+
+// reportOptions lê do settings.json as preferências dos relatórios
+func (a *App) reportOptions() usecase.ReportOptions {
+	return usecase.ReportOptions{ExcludeMaintenance: a.cfg.GetMaintenance().ExcludeFromReports}
+}
+
+// buildReport calcula o relatório usando as preferências do settings.json
+func (a *App) buildReport(hostID string, startStr, endStr string) (domain.ReportData, error) {
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return domain.ReportData{}, err
+	}
+	opts := a.reportOptions()
+	return a.service.BuildReport(hostID, start, end, opts)
+}
+
+// GetHTMLReport gera o relatório como uma página HTML única (gráficos embutidos) em
+// Downloads e retorna o caminho
+func (a *App) GetHTMLReport(hostID string, startStr, endStr string) (string, error) {
+	data, err := a.buildReport(hostID, startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+	return exportFile("RELATORIO", []string{hostID}, ".html", func(w io.Writer) error {
+		return report.HTML(w, data)
+	})
+}
 
-	summaryPath := filepath.Join(home, "Downloads", fmt.Sprintf("RESUMO-%s-%d.txt", hostID, timestamp))
-	os.WriteFile(summaryPath, []byte(summary), 0644)
+// GetPDFReport gera o relatório em PDF (capa, gráficos, SLA, incidentes e anexo com
+// as amostras) em Downloads e retorna o caminho
+func (a *App) GetPDFReport(hostID string, startStr, endStr string) (string, error) {
+	data, err := a.buildReport(hostID, startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+	samples, err := a.service.ReportSamples(data)
+	if err != nil {
+		return "", err
+	}
+	return exportFile("RELATORIO", []string{hostID}, ".pdf", func(w io.Writer) error {
+		return report.PDF(w, data, samples)
+	})
+}
 
-	rawPath := filepath.Join(home, "Downloads", fmt.Sprintf("DADOS-TECNICOS-%s-%d.csv", hostID, timestamp))
-	os.WriteFile(rawPath, []byte(raw), 0644)
+// GetMarkdownReport gera o relatório em Markdown, para colar em chamados e wikis
+func (a *App) GetMarkdownReport(hostID string, startStr, endStr string) (string, error) {
+	data, err := a.buildReport(hostID, startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+	return exportFile("RELATORIO", []string{hostID}, ".md", func(w io.Writer) error {
+		return report.Markdown(w, data)
+	})
+}
+
+// GetJSONReport grava os dados do relatório em JSON, para scripts
+func (a *App) GetJSONReport(hostID string, startStr, endStr string) (string, error) {
+	data, err := a.buildReport(hostID, startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+	return exportFile("RELATORIO", []string{hostID}, ".json", func(w io.Writer) error {
+		return report.JSON(w, data)
+	})
+}
+
+// GetPeriodComparison compara o host em dois períodos (ex.: antes × depois da troca do
+// roteador) em Downloads e retorna o caminho. Sem período base, compara com o período
+// anterior de mesmo tamanho. format: "html" (padrão), "md" ou "json".
+func (a *App) GetPeriodComparison(hostID, baseStartStr, baseEndStr, startStr, endStr, format string) (string, error) {
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+	// Sem período base, ComparePeriods usa o anterior de mesmo tamanho
+	var baseStart, baseEnd time.Time
+	if baseStartStr != "" || baseEndStr != "" {
+		if baseStart, baseEnd, err = parseRange(baseStartStr, baseEndStr); err != nil {
+			return "", err
+		}
+	}
+	opts := a.reportOptions()
+	cmp, err := a.service.ComparePeriods(hostID, baseStart, baseEnd, start, end, opts)
+	if err != nil {
+		return "", err
+	}
 
-	return summaryPath, nil
+	write, ext := func(w io.Writer) error { return report.PeriodsHTML(w, cmp) }, ".html"
+	switch format {
+	case "", "html":
+	case "md":
+		write, ext = func(w io.Writer) error { return report.PeriodsMarkdown(w, cmp) }, ".md"
+	case "json":
+		write, ext = func(w io.Writer) error { return report.JSON(w, cmp) }, ".json"
+	default:
+		return "", fmt.Errorf("formato desconhecido: %s", format)
+	}
+	return exportFile("COMPARATIVO-PERIODOS", []string{hostID}, ext, write)
 }
 
 func (a *App) OpenPath(path string) {
@@ -120,8 +338,17 @@ func (a *App) OpenPath(path string) {
 	}
 }
 
-func (a *App) GetDiagramConfig() config.NetworkDiagramConfig {
-	return a.cfg.Data.NetworkDiagram
+// GetTopology retorna o grafo de dependências entre os alvos com o status ao vivo
+func (a *App) GetTopology() []domain.TopologyNode {
+	return a.service.GetTopology()
+}
+
+// SetTargetParent define de qual alvo o host depende (vazio remove a dependência)
+func (a *App) SetTargetParent(hostID string, parentID string) error {
+	if err := a.service.SetHostParent(hostID, parentID); err != nil {
+		return err
+	}
+	return a.cfg.UpdateTargetParent(hostID, parentID)
 }
 
 func (a *App) SetTargetDiagramVisibility(hostID string, show bool) {
@@ -131,3 +358,303 @@ func (a *App) SetTargetDiagramVisibility(hostID string, show bool) {
 	// 2. Se você precisar que o Service saiba disso em tempo real:
 	// a.service.ToggleDiagramStatus(hostID, show)
 }
+
+// --- RETENÇÃO ---
+
+// SetTargetRetention define por quantos dias as amostras brutas do host são mantidas
+// (0 usa o retention_days global) e aplica a retenção em seguida
+func (a *App) SetTargetRetention(hostID string, days int) error {
+	a.service.SetHostRetention(hostID, days)
+	if err := a.cfg.UpdateTargetRetention(hostID, days); err != nil {
+		return err
+	}
+	a.service.TriggerCleanup()
+	return nil
+}
+
+// RunRetentionCleanup aplica a retenção agora e retorna o que foi removido
+func (a *App) RunRetentionCleanup() (domain.CleanupReport, error) {
+	return a.service.RunCleanup()
+}
+
+// GetLastCleanup retorna o resultado da última limpeza (nil se ainda não rodou)
+func (a *App) GetLastCleanup() *domain.CleanupReport {
+	return a.service.LastCleanup()
+}
+
+// --- BACKUP ---
+
+// CreateBackup grava agora um snapshot consistente do banco
+func (a *App) CreateBackup() (domain.Snapshot, error) {
+	return a.service.RunBackup()
+}
+
+// ListBackups retorna os snapshots, do mais recente para o mais antigo
+func (a *App) ListBackups() ([]domain.Snapshot, error) {
+	return a.service.ListBackups()
+}
+
+// RestoreBackup agenda a restauração do snapshot; ela é aplicada ao reiniciar o app
+func (a *App) RestoreBackup(name string) error {
+	return a.service.RestoreBackup(name)
+}
+
+// --- IMPORTAÇÃO / EXPORTAÇÃO ---
+
+// ExportHistory grava as amostras brutas dos hosts no período em Downloads, no formato
+// pedido (csv, jsonl, influx, parquet ou dados-tecnicos), e retorna o caminho do arquivo
+func (a *App) ExportHistory(hostIDs []string, startStr, endStr string, format string) (string, error) {
+	f, err := transfer.ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if f == transfer.FormatReport && len(hostIDs) > 1 {
+		return "", fmt.Errorf("o formato %s comporta apenas um host por arquivo", f)
+	}
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+
+	return exportFile("HISTORICO", hostIDs, f.Extension(), func(w io.Writer) error {
+		enc := transfer.NewEncoder(w, f)
+		if _, err := a.service.ExportHistory(hostIDs, start, end, enc.Write); err != nil {
+			return err
+		}
+		return enc.Flush()
+	})
+}
+
+// ExportParquet grava os hosts no período em Parquet, para análise no DuckDB/Pandas.
+// resolution escolhe o nível: raw (amostras), 1m ou 1h (agregados).
+func (a *App) ExportParquet(hostIDs []string, startStr, endStr string, resolution string) (string, error) {
+	res := domain.Resolution(resolution)
+	if res == domain.ResolutionRaw || res == "" {
+		return a.ExportHistory(hostIDs, startStr, endStr, string(transfer.FormatParquet))
+	}
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+
+	return exportFile("HISTORICO-"+resolution, hostIDs, transfer.FormatParquet.Extension(), func(w io.Writer) error {
+		enc := transfer.NewRollupEncoder(w)
+		if _, err := a.service.ExportRollups(hostIDs, res, start, end, enc.Write); err != nil {
+			return err
+		}
+		return enc.Close()
+	})
+}
+
+// fileSafe troca por "_" tudo que não for letra, dígito, ".", "-" ou "_": nomes vindos
+// do usuário (grupos, IDs) não podem levar separadores de pasta para fora de Downloads
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// exportFile cria o arquivo de exportação em Downloads; em caso de erro ele é removido
+func exportFile(prefix string, hostIDs []string, ext string, write func(w io.Writer) error) (string, error) {
+	if len(hostIDs) == 0 {
+		return "", fmt.Errorf("nenhum host selecionado")
+	}
+	if len(hostIDs) == 1 {
+		prefix += "-" + fileSafe(hostIDs[0])
+	}
+	home, _ := os.UserHomeDir()
+	path := filepath.Join(home, "Downloads", fmt.Sprintf("%s-%d%s", prefix, time.Now().Unix(), ext))
+
+	out, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	err = write(out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// ImportHistory mescla no histórico as amostras de um arquivo exportado (ou da planilha
+// DADOS-TECNICOS do relatório). format vazio detecta pelo arquivo; hostID é usado
+// quando o arquivo não identifica o host.
+func (a *App) ImportHistory(path string, format string, hostID string) (domain.ImportReport, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return domain.ImportReport{}, err
+	}
+	defer in.Close()
+
+	br := bufio.NewReader(in)
+	var f transfer.Format
+	if format != "" {
+		if f, err = transfer.ParseFormat(format); err != nil {
+			return domain.ImportReport{}, err
+		}
+	} else {
+		head, _ := br.Peek(512)
+		f = transfer.Detect(path, head)
+	}
+	if hostID == "" && f == transfer.FormatReport {
+		hostID = transfer.HostFromReportName(path)
+	}
+
+	return a.service.ImportHistory(string(f), f.Precision(), func(fn func(domain.PingResult) error) (int, error) {
+		return transfer.Decode(br, f, hostID, fn)
+	})
+}
+
+// --- ANOTAÇÕES ---
+
+// AddAnnotation grava uma nota na linha do tempo. start/end são epoch em segundos
+// (start 0 usa o momento atual, end 0 marca um instante); hostID vazio vale para
+// todos os hosts.
+func (a *App) AddAnnotation(hostID string, text string, startUnix, endUnix int64) (domain.Annotation, error) {
+	ann := domain.Annotation{HostID: hostID, Text: text}
+	if startUnix > 0 {
+		ann.Start = time.Unix(startUnix, 0)
+	}
+	if endUnix > 0 {
+		end := time.Unix(endUnix, 0)
+		ann.End = &end
+	}
+	return a.service.AddAnnotation(ann)
+}
+
+// ListAnnotations retorna as anotações do período (as do host e as globais)
+func (a *App) ListAnnotations(hostID string, startUnix, endUnix int64) ([]domain.Annotation, error) {
+	return a.service.ListAnnotations(hostID, time.Unix(startUnix, 0), time.Unix(endUnix, 0))
+}
+
+func (a *App) DeleteAnnotation(id int64) error {
+	return a.service.DeleteAnnotation(id)
+}
+
+// --- GRUPOS (TAGS) ---
+
+func (a *App) SetTargetTags(hostID string, tags []string) {
+	a.service.SetHostTags(hostID, tags)
+	a.cfg.UpdateTargetTags(hostID, tags)
+}
+
+// GetGroups retorna as estatísticas ao vivo de cada grupo
+func (a *App) GetGroups() []domain.GroupStats {
+	return a.service.GetGroups()
+}
+
+// GetGroupStats retorna as estatísticas históricas de um grupo no período
+func (a *App) GetGroupStats(group string, startStr, endStr string) (domain.GroupStats, error) {
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return domain.GroupStats{}, err
+	}
+	return a.service.GetGroupStats(group, start, end)
+}
+
+// GetGroupReport grava o resumo do grupo em Downloads e retorna o caminho
+func (a *App) GetGroupReport(group string, startStr, endStr string) (string, error) {
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return "", err
+	}
+
+	report, err := a.service.GenerateGroupReport(group, start, end)
+	if err != nil {
+		return "", err
+	}
+	return exportFile("GRUPO", []string{group}, ".txt", func(w io.Writer) error {
+		_, err := io.WriteString(w, report)
+		return err
+	})
+}
+
+// --- JANELAS DE MANUTENÇÃO ---
+
+func (a *App) GetMaintenanceWindows() []domain.MaintenanceWindow {
+	return a.cfg.GetMaintenance().Windows
+}
+
+func (a *App) AddMaintenanceWindow(w domain.MaintenanceWindow) (domain.MaintenanceWindow, error) {
+	if err := usecase.ValidateMaintenanceWindow(w); err != nil {
+		return w, err
+	}
+	if w.ID == "" {
+		w.ID = fmt.Sprintf("mw-%d", time.Now().UnixNano())
+	}
+
+	if err := a.cfg.AddMaintenanceWindow(w); err != nil {
+		return w, err
+	}
+	return w, a.service.SetMaintenanceWindows(a.cfg.GetMaintenance().Windows)
+}
+
+func (a *App) RemoveMaintenanceWindow(id string) error {
+	if err := a.cfg.RemoveMaintenanceWindow(id); err != nil {
+		return err
+	}
+	return a.service.SetMaintenanceWindows(a.cfg.GetMaintenance().Windows)
+}
+
+// --- SLA ---
+
+// GetSLA calcula a disponibilidade do host no período com a política do settings.json
+func (a *App) GetSLA(hostID string, startStr, endStr string) (domain.SLAReport, error) {
+	return a.GetSLAWithPolicy(hostID, startStr, endStr, a.cfg.GetSLA())
+}
+
+// GetSLAWithPolicy permite simular outra definição de disponibilidade sem alterar a configuração
+func (a *App) GetSLAWithPolicy(hostID string, startStr, endStr string, policy domain.SLAPolicy) (domain.SLAReport, error) {
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return domain.SLAReport{}, err
+	}
+	opts := a.reportOptions()
+	return a.service.GetSLA(hostID, start, end, policy, opts)
+}
+
+// GetBaseline retorna o comportamento aprendido do host por hora da semana
+func (a *App) GetBaseline(hostID string) []domain.BaselineSlot {
+	return a.service.GetBaseline(hostID)
+}
+
+// GetStorageHealth informa se as amostras estão sendo gravadas e quantas aguardam no buffer
+func (a *App) GetStorageHealth() domain.StorageHealth {
+	if hr, ok := a.repo.(domain.HealthReporter); ok {
+		return hr.Health()
+	}
+	return domain.StorageHealth{Healthy: true}
+}
+
+// --- ESTATÍSTICAS ---
+
+// GetStats resume o período do host (contagem, perda, mín/méd/máx e percentis)
+func (a *App) GetStats(hostID string, startStr, endStr string) (domain.RangeStats, error) {
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return domain.RangeStats{}, err
+	}
+	return a.service.GetStats(hostID, start, end)
+}
+
+// GetHistogram distribui as latências do período em faixas de binMs milissegundos
+func (a *App) GetHistogram(hostID string, startStr, endStr string, binMs int64) ([]domain.HistogramBin, error) {
+	start, end, err := parseRange(startStr, endStr)
+	if err != nil {
+		return nil, err
+	}
+	return a.service.GetHistogram(hostID, start, end, binMs)
+}
+
+// GetHistory retorna a série histórica dos hosts entre dois instantes (epoch em
+// segundos), em intervalos de bucketSec segundos (0 escolhe automaticamente)
+func (a *App) GetHistory(hostIDs []string, startUnix, endUnix int64, bucketSec int64) ([]domain.HostSeries, error) {
+	return a.service.GetHistorySeries(hostIDs, time.Unix(startUnix, 0), time.Unix(endUnix, 0), time.Duration(bucketSec)*time.Second)
+}
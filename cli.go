@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"lag-monitor/internal/config"
+	"lag-monitor/internal/infra/database"
+	"os"
+)
+
+// runCLI executa os subcomandos de linha de comando. Retorna false quando não há
+// subcomando conhecido, e o app abre normalmente.
+//
+//	lagmon backup            cria um snapshot (pode rodar com o app aberto)
+//	lagmon snapshots         lista os snapshots
+//	lagmon restore <nome>    agenda a restauração para a próxima abertura do app
+func runCLI(args []string, sc config.StorageConfig) (bool, int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+
+	switch args[0] {
+	case "backup", "snapshots", "restore":
+	default:
+		return false, 0
+	}
+
+	if sc.Backend != "" && sc.Backend != config.BackendSQLite {
+		fmt.Fprintf(os.Stderr, "backup disponível apenas para o backend sqlite (atual: %s)\n", sc.Backend)
+		return true, 1
+	}
+	dbPath := sc.Path
+	if dbPath == "" {
+		dbPath = database.GetDatabasePath()
+	}
+
+	switch args[0] {
+	case "backup":
+		snap, err := database.BackupFile(dbPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Erro ao criar backup:", err)
+			return true, 1
+		}
+		fmt.Printf("Backup criado: %s (%d bytes)\n", snap.Path, snap.Size)
+
+	case "snapshots":
+		snaps, err := database.ListSnapshots(dbPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Erro ao listar snapshots:", err)
+			return true, 1
+		}
+		for _, s := range snaps {
+			fmt.Printf("%s\t%s\t%d bytes\n", s.Name, s.CreatedAt.Format("02/01/2006 15:04:05"), s.Size)
+		}
+
+	case "restore":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "uso: lagmon restore <nome do snapshot>")
+			return true, 2
+		}
+		if err := database.StageRestore(dbPath, args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, "Erro ao restaurar:", err)
+			return true, 1
+		}
+		fmt.Println("Restauração agendada: será aplicada na próxima abertura do app.")
+	}
+	return true, 0
+}
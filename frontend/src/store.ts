@@ -9,6 +9,7 @@ export interface HostDef {
     active: boolean;
     isGateway: boolean; // Certifique-se de que este campo existe aqui
     showInDiagram: boolean;
+    parentId: string; // Host do qual este depende (vazio = raiz)
     stats: { latency: number; jitter: number; loss: boolean };
 }
 
@@ -24,7 +25,7 @@ const NEON_PALETTE = [
 export const store = reactive({
   targets: [] as HostDef[],
 
-addTarget(id: string, ip: string, name: string, active: boolean, isGateway: boolean, showInDiagram: boolean) {    if (this.targets.find((t) => t.id === id)) return;
+addTarget(id: string, ip: string, name: string, active: boolean, isGateway: boolean, showInDiagram: boolean, parentId = "") {    if (this.targets.find((t) => t.id === id)) return;
 
     const color = NEON_PALETTE[this.targets.length % NEON_PALETTE.length];
     this.targets.push({
@@ -33,6 +34,7 @@ addTarget(id: string, ip: string, name: string, active: boolean, isGateway: bool
         active: active,
         isGateway: isGateway, // Mapeia o valor recebido
         showInDiagram: showInDiagram,
+        parentId: parentId,
         stats: { latency: 0, jitter: 0, loss: false }
     });
   },
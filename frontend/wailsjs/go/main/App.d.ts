@@ -2,23 +2,90 @@
 // This file is automatically generated. DO NOT EDIT
 import {domain} from '../models';
 import {config} from '../models';
+import {usecase} from '../models';
+
+export function AddAnnotation(arg1:string,arg2:string,arg3:number,arg4:number):Promise<domain.Annotation>;
+
+export function AddMaintenanceWindow(arg1:domain.MaintenanceWindow):Promise<domain.MaintenanceWindow>;
 
 export function AddTarget(arg1:string,arg2:string):Promise<domain.Host>;
 
+export function CreateBackup():Promise<domain.Snapshot>;
+
+export function DeleteAnnotation(arg1:number):Promise<void>;
+
+export function ExportHistory(arg1:Array<string>,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function ExportParquet(arg1:Array<string>,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function GetBaseline(arg1:string):Promise<Array<domain.BaselineSlot>>;
+
 export function GetConfig():Promise<config.AppConfig>;
 
-export function GetDiagramConfig():Promise<config.NetworkDiagramConfig>;
+export function GetGroupReport(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function GetGroupStats(arg1:string,arg2:string,arg3:string):Promise<domain.GroupStats>;
+
+export function GetGroups():Promise<Array<domain.GroupStats>>;
+
+export function GetHTMLReport(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function GetHistogram(arg1:string,arg2:string,arg3:string,arg4:number):Promise<Array<domain.HistogramBin>>;
+
+export function GetHistory(arg1:Array<string>,arg2:number,arg3:number,arg4:number):Promise<Array<domain.HostSeries>>;
+
+export function GetJSONReport(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function GetLastCleanup():Promise<domain.CleanupReport>;
+
+export function GetMaintenanceWindows():Promise<Array<domain.MaintenanceWindow>>;
+
+export function GetMarkdownReport(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function GetPDFReport(arg1:string,arg2:string,arg3:string):Promise<string>;
 
-export function GetReport(arg1:string,arg2:string,arg3:string):Promise<string>;
+export function GetPeriodComparison(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<string>;
+
+export function GetReport(arg1:Array<string>,arg2:string,arg3:string):Promise<string>;
+
+export function GetReportWithOptions(arg1:string,arg2:string,arg3:string,arg4:usecase.ReportOptions):Promise<string>;
+
+export function GetSLA(arg1:string,arg2:string,arg3:string):Promise<domain.SLAReport>;
+
+export function GetSLAWithPolicy(arg1:string,arg2:string,arg3:string,arg4:domain.SLAPolicy):Promise<domain.SLAReport>;
+
+export function GetStats(arg1:string,arg2:string,arg3:string):Promise<domain.RangeStats>;
+
+export function GetStorageHealth():Promise<domain.StorageHealth>;
 
 export function GetTargets():Promise<Array<domain.Host>>;
 
+export function GetTopology():Promise<Array<domain.TopologyNode>>;
+
+export function ImportHistory(arg1:string,arg2:string,arg3:string):Promise<domain.ImportReport>;
+
+export function ListAnnotations(arg1:string,arg2:number,arg3:number):Promise<Array<domain.Annotation>>;
+
+export function ListBackups():Promise<Array<domain.Snapshot>>;
+
 export function OpenPath(arg1:string):Promise<void>;
 
+export function RemoveMaintenanceWindow(arg1:string):Promise<void>;
+
 export function RemoveTarget(arg1:string):Promise<void>;
 
+export function RestoreBackup(arg1:string):Promise<void>;
+
+export function RunRetentionCleanup():Promise<domain.CleanupReport>;
+
 export function SetTargetActive(arg1:string,arg2:boolean):Promise<void>;
 
 export function SetTargetDiagramVisibility(arg1:string,arg2:boolean):Promise<void>;
 
+export function SetTargetParent(arg1:string,arg2:string):Promise<void>;
+
+export function SetTargetRetention(arg1:string,arg2:number):Promise<void>;
+
+export function SetTargetTags(arg1:string,arg2:Array<string>):Promise<void>;
+
 export function UpdateConfig(arg1:config.AppConfig):Promise<void>;
@@ -2,34 +2,154 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAnnotation(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['AddAnnotation'](arg1, arg2, arg3, arg4);
+}
+
+export function AddMaintenanceWindow(arg1) {
+  return window['go']['main']['App']['AddMaintenanceWindow'](arg1);
+}
+
 export function AddTarget(arg1, arg2) {
   return window['go']['main']['App']['AddTarget'](arg1, arg2);
 }
 
+export function CreateBackup() {
+  return window['go']['main']['App']['CreateBackup']();
+}
+
+export function DeleteAnnotation(arg1) {
+  return window['go']['main']['App']['DeleteAnnotation'](arg1);
+}
+
+export function ExportHistory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportHistory'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportParquet(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportParquet'](arg1, arg2, arg3, arg4);
+}
+
+export function GetBaseline(arg1) {
+  return window['go']['main']['App']['GetBaseline'](arg1);
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
-export function GetDiagramConfig() {
-  return window['go']['main']['App']['GetDiagramConfig']();
+export function GetGroupReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetGroupReport'](arg1, arg2, arg3);
+}
+
+export function GetGroupStats(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetGroupStats'](arg1, arg2, arg3);
+}
+
+export function GetGroups() {
+  return window['go']['main']['App']['GetGroups']();
+}
+
+export function GetHTMLReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetHTMLReport'](arg1, arg2, arg3);
+}
+
+export function GetHistogram(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetHistogram'](arg1, arg2, arg3, arg4);
+}
+
+export function GetHistory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetHistory'](arg1, arg2, arg3, arg4);
+}
+
+export function GetJSONReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetJSONReport'](arg1, arg2, arg3);
+}
+
+export function GetLastCleanup() {
+  return window['go']['main']['App']['GetLastCleanup']();
+}
+
+export function GetMaintenanceWindows() {
+  return window['go']['main']['App']['GetMaintenanceWindows']();
+}
+
+export function GetMarkdownReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetMarkdownReport'](arg1, arg2, arg3);
+}
+
+export function GetPDFReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetPDFReport'](arg1, arg2, arg3);
+}
+
+export function GetPeriodComparison(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['GetPeriodComparison'](arg1, arg2, arg3, arg4, arg5, arg6);
 }
 
 export function GetReport(arg1, arg2, arg3) {
   return window['go']['main']['App']['GetReport'](arg1, arg2, arg3);
 }
 
+export function GetReportWithOptions(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetReportWithOptions'](arg1, arg2, arg3, arg4);
+}
+
+export function GetSLA(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetSLA'](arg1, arg2, arg3);
+}
+
+export function GetSLAWithPolicy(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetSLAWithPolicy'](arg1, arg2, arg3, arg4);
+}
+
+export function GetStats(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetStats'](arg1, arg2, arg3);
+}
+
+export function GetStorageHealth() {
+  return window['go']['main']['App']['GetStorageHealth']();
+}
+
 export function GetTargets() {
   return window['go']['main']['App']['GetTargets']();
 }
 
+export function GetTopology() {
+  return window['go']['main']['App']['GetTopology']();
+}
+
+export function ImportHistory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportHistory'](arg1, arg2, arg3);
+}
+
+export function ListAnnotations(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ListAnnotations'](arg1, arg2, arg3);
+}
+
+export function ListBackups() {
+  return window['go']['main']['App']['ListBackups']();
+}
+
 export function OpenPath(arg1) {
   return window['go']['main']['App']['OpenPath'](arg1);
 }
 
+export function RemoveMaintenanceWindow(arg1) {
+  return window['go']['main']['App']['RemoveMaintenanceWindow'](arg1);
+}
+
 export function RemoveTarget(arg1) {
   return window['go']['main']['App']['RemoveTarget'](arg1);
 }
 
+export function RestoreBackup(arg1) {
+  return window['go']['main']['App']['RestoreBackup'](arg1);
+}
+
+export function RunRetentionCleanup() {
+  return window['go']['main']['App']['RunRetentionCleanup']();
+}
+
 export function SetTargetActive(arg1, arg2) {
   return window['go']['main']['App']['SetTargetActive'](arg1, arg2);
 }
@@ -38,6 +158,18 @@ export function SetTargetDiagramVisibility(arg1, arg2) {
   return window['go']['main']['App']['SetTargetDiagramVisibility'](arg1, arg2);
 }
 
+export function SetTargetParent(arg1, arg2) {
+  return window['go']['main']['App']['SetTargetParent'](arg1, arg2);
+}
+
+export function SetTargetRetention(arg1, arg2) {
+  return window['go']['main']['App']['SetTargetRetention'](arg1, arg2);
+}
+
+export function SetTargetTags(arg1, arg2) {
+  return window['go']['main']['App']['SetTargetTags'](arg1, arg2);
+}
+
 export function UpdateConfig(arg1) {
   return window['go']['main']['App']['UpdateConfig'](arg1);
 }
@@ -1,5 +1,71 @@
 export namespace config {
 	
+	export class StorageConfig {
+	    backend: string;
+	    path: string;
+	    memory_samples: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.backend = source["backend"];
+	        this.path = source["path"];
+	        this.memory_samples = source["memory_samples"];
+	    }
+	}
+	export class MaintenanceConfig {
+	    windows: domain.MaintenanceWindow[];
+	    exclude_from_reports: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new MaintenanceConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.windows = this.convertValues(source["windows"], domain.MaintenanceWindow);
+	        this.exclude_from_reports = source["exclude_from_reports"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class HookConfig {
+	    event: string;
+	    command: string;
+	    timeout_sec: number;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new HookConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.event = source["event"];
+	        this.command = source["command"];
+	        this.timeout_sec = source["timeout_sec"];
+	        this.enabled = source["enabled"];
+	    }
+	}
 	export class DiagramNode {
 	    name: string;
 	    ip: string;
@@ -48,10 +114,31 @@ export namespace config {
 		    return a;
 		}
 	}
+	export class RollupRetention {
+	    minute_days: number;
+	    hour_days: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RollupRetention(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.minute_days = source["minute_days"];
+	        this.hour_days = source["hour_days"];
+	    }
+	}
 	export class AppConfig {
 	    retention_days: number;
-	    network_diagram: NetworkDiagramConfig;
+	    rollup_retention: RollupRetention;
+	    network_diagram?: NetworkDiagramConfig;
 	    targets: domain.Host[];
+	    hooks: HookConfig[];
+	    alert_rules: domain.AlertRule[];
+	    maintenance: MaintenanceConfig;
+	    sla: domain.SLAPolicy;
+	    storage: StorageConfig;
+	    backup: domain.BackupPolicy;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppConfig(source);
@@ -60,8 +147,15 @@ export namespace config {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.retention_days = source["retention_days"];
+	        this.rollup_retention = this.convertValues(source["rollup_retention"], RollupRetention);
 	        this.network_diagram = this.convertValues(source["network_diagram"], NetworkDiagramConfig);
 	        this.targets = this.convertValues(source["targets"], domain.Host);
+	        this.hooks = this.convertValues(source["hooks"], HookConfig);
+	        this.alert_rules = this.convertValues(source["alert_rules"], domain.AlertRule);
+	        this.maintenance = this.convertValues(source["maintenance"], MaintenanceConfig);
+	        this.sla = this.convertValues(source["sla"], domain.SLAPolicy);
+	        this.storage = this.convertValues(source["storage"], StorageConfig);
+	        this.backup = this.convertValues(source["backup"], domain.BackupPolicy);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -83,11 +177,205 @@ export namespace config {
 		}
 	}
 	
+	
+	
+	
+	
 
 }
 
 export namespace domain {
 	
+	export class AlertRule {
+	    group: string;
+	    max_latency_ms: number;
+	    max_loss_pct: number;
+	    min_down: number;
+	    enabled: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AlertRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.group = source["group"];
+	        this.max_latency_ms = source["max_latency_ms"];
+	        this.max_loss_pct = source["max_loss_pct"];
+	        this.min_down = source["min_down"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	export class Annotation {
+	    id: number;
+	    hostId?: string;
+	    // Go type: time
+	    start: any;
+	    // Go type: time
+	    end?: any;
+	    text: string;
+	    kind: string;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Annotation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.hostId = source["hostId"];
+	        this.start = this.convertValues(source["start"], null);
+	        this.end = this.convertValues(source["end"], null);
+	        this.text = source["text"];
+	        this.kind = source["kind"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BackupPolicy {
+	    enabled: boolean;
+	    interval_hours: number;
+	    keep: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.interval_hours = source["interval_hours"];
+	        this.keep = source["keep"];
+	    }
+	}
+	export class BaselineSlot {
+	    hourOfWeek: number;
+	    latencyMs: number;
+	    latencyDev: number;
+	    lossPct: number;
+	    lossDev: number;
+	    minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BaselineSlot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hourOfWeek = source["hourOfWeek"];
+	        this.latencyMs = source["latencyMs"];
+	        this.latencyDev = source["latencyDev"];
+	        this.lossPct = source["lossPct"];
+	        this.lossDev = source["lossDev"];
+	        this.minutes = source["minutes"];
+	    }
+	}
+	export class CleanupReport {
+	    rawDeleted: number;
+	    minuteDeleted: number;
+	    hourDeleted: number;
+	    bytesReclaimed: number;
+	    durationMs: number;
+	    error?: string;
+	    // Go type: time
+	    timestamp: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rawDeleted = source["rawDeleted"];
+	        this.minuteDeleted = source["minuteDeleted"];
+	        this.hourDeleted = source["hourDeleted"];
+	        this.bytesReclaimed = source["bytesReclaimed"];
+	        this.durationMs = source["durationMs"];
+	        this.error = source["error"];
+	        this.timestamp = this.convertValues(source["timestamp"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GroupStats {
+	    group: string;
+	    hosts: number;
+	    down: number;
+	    samples: number;
+	    avgLatency: number;
+	    minLatency: number;
+	    maxLatency: number;
+	    avgJitter: number;
+	    lossPct: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GroupStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.group = source["group"];
+	        this.hosts = source["hosts"];
+	        this.down = source["down"];
+	        this.samples = source["samples"];
+	        this.avgLatency = source["avgLatency"];
+	        this.minLatency = source["minLatency"];
+	        this.maxLatency = source["maxLatency"];
+	        this.avgJitter = source["avgJitter"];
+	        this.lossPct = source["lossPct"];
+	    }
+	}
+	export class HistogramBin {
+	    from: number;
+	    to: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistogramBin(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.count = source["count"];
+	    }
+	}
 	export class Host {
 	    id: string;
 	    name: string;
@@ -95,6 +383,9 @@ export namespace domain {
 	    isGateway: boolean;
 	    active: boolean;
 	    showInDiagram: boolean;
+	    parentId?: string;
+	    tags?: string[];
+	    retentionDays?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Host(source);
@@ -108,6 +399,423 @@ export namespace domain {
 	        this.isGateway = source["isGateway"];
 	        this.active = source["active"];
 	        this.showInDiagram = source["showInDiagram"];
+	        this.parentId = source["parentId"];
+	        this.tags = source["tags"];
+	        this.retentionDays = source["retentionDays"];
+	    }
+	}
+	export class SeriesPoint {
+	    // Go type: time
+	    time: any;
+	    count: number;
+	    latMin: number;
+	    latAvg: number;
+	    latMax: number;
+	    jitterAvg: number;
+	    lossRatio: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SeriesPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.count = source["count"];
+	        this.latMin = source["latMin"];
+	        this.latAvg = source["latAvg"];
+	        this.latMax = source["latMax"];
+	        this.jitterAvg = source["jitterAvg"];
+	        this.lossRatio = source["lossRatio"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class HostSeries {
+	    hostId: string;
+	    resolution: string;
+	    bucketSec: number;
+	    points: SeriesPoint[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HostSeries(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostId = source["hostId"];
+	        this.resolution = source["resolution"];
+	        this.bucketSec = source["bucketSec"];
+	        this.points = this.convertValues(source["points"], SeriesPoint);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportReport {
+	    format: string;
+	    hostIds: string[];
+	    read: number;
+	    imported: number;
+	    duplicates: number;
+	    invalid: number;
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.hostIds = source["hostIds"];
+	        this.read = source["read"];
+	        this.imported = source["imported"];
+	        this.duplicates = source["duplicates"];
+	        this.invalid = source["invalid"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	export class MaintenanceWindow {
+	    id: string;
+	    name: string;
+	    host_id?: string;
+	    group?: string;
+	    // Go type: time
+	    start?: any;
+	    // Go type: time
+	    end?: any;
+	    cron?: string;
+	    duration_min?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MaintenanceWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.host_id = source["host_id"];
+	        this.group = source["group"];
+	        this.start = this.convertValues(source["start"], null);
+	        this.end = this.convertValues(source["end"], null);
+	        this.cron = source["cron"];
+	        this.duration_min = source["duration_min"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RangeStats {
+	    hostId: string;
+	    resolution: string;
+	    // Go type: time
+	    start: any;
+	    // Go type: time
+	    end: any;
+	    count: number;
+	    lossCount: number;
+	    lossPct: number;
+	    latMin: number;
+	    latAvg: number;
+	    latMax: number;
+	    latP50: number;
+	    latP95: number;
+	    latP99: number;
+	    jitterAvg: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RangeStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostId = source["hostId"];
+	        this.resolution = source["resolution"];
+	        this.start = this.convertValues(source["start"], null);
+	        this.end = this.convertValues(source["end"], null);
+	        this.count = source["count"];
+	        this.lossCount = source["lossCount"];
+	        this.lossPct = source["lossPct"];
+	        this.latMin = source["latMin"];
+	        this.latAvg = source["latAvg"];
+	        this.latMax = source["latMax"];
+	        this.latP50 = source["latP50"];
+	        this.latP95 = source["latP95"];
+	        this.latP99 = source["latP99"];
+	        this.jitterAvg = source["jitterAvg"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SLAPolicy {
+	    definition: string;
+	    latency_threshold_ms: number;
+	    target_pct: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SLAPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.definition = source["definition"];
+	        this.latency_threshold_ms = source["latency_threshold_ms"];
+	        this.target_pct = source["target_pct"];
+	    }
+	}
+	export class SLAReport {
+	    hostId: string;
+	    definition: string;
+	    // Go type: time
+	    start: any;
+	    // Go type: time
+	    end: any;
+	    samples: number;
+	    availability: number;
+	    outages: number;
+	    downtimeSec: number;
+	    mtbfSec: number;
+	    mttrSec: number;
+	    targetPct: number;
+	    metTarget: boolean;
+	    estimated?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SLAReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hostId = source["hostId"];
+	        this.definition = source["definition"];
+	        this.start = this.convertValues(source["start"], null);
+	        this.end = this.convertValues(source["end"], null);
+	        this.samples = source["samples"];
+	        this.availability = source["availability"];
+	        this.outages = source["outages"];
+	        this.downtimeSec = source["downtimeSec"];
+	        this.mtbfSec = source["mtbfSec"];
+	        this.mttrSec = source["mttrSec"];
+	        this.targetPct = source["targetPct"];
+	        this.metTarget = source["metTarget"];
+	        this.estimated = source["estimated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Snapshot {
+	    name: string;
+	    path: string;
+	    size: number;
+	    // Go type: time
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Snapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StorageHealth {
+	    healthy: boolean;
+	    buffered: number;
+	    capacity: number;
+	    dropped: number;
+	    consecutiveFailures: number;
+	    lastError?: string;
+	    // Go type: time
+	    lastErrorAt?: any;
+	    // Go type: time
+	    lastFlushAt?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageHealth(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.healthy = source["healthy"];
+	        this.buffered = source["buffered"];
+	        this.capacity = source["capacity"];
+	        this.dropped = source["dropped"];
+	        this.consecutiveFailures = source["consecutiveFailures"];
+	        this.lastError = source["lastError"];
+	        this.lastErrorAt = this.convertValues(source["lastErrorAt"], null);
+	        this.lastFlushAt = this.convertValues(source["lastFlushAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TopologyNode {
+	    id: string;
+	    name: string;
+	    ip: string;
+	    isGateway: boolean;
+	    active: boolean;
+	    showInDiagram: boolean;
+	    parentId?: string;
+	    tags?: string[];
+	    retentionDays?: number;
+	    status: string;
+	    latency: number;
+	    suppressed: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TopologyNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.ip = source["ip"];
+	        this.isGateway = source["isGateway"];
+	        this.active = source["active"];
+	        this.showInDiagram = source["showInDiagram"];
+	        this.parentId = source["parentId"];
+	        this.tags = source["tags"];
+	        this.retentionDays = source["retentionDays"];
+	        this.status = source["status"];
+	        this.latency = source["latency"];
+	        this.suppressed = source["suppressed"];
+	    }
+	}
+
+}
+
+export namespace usecase {
+	
+	export class ReportOptions {
+	    excludeMaintenance: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.excludeMaintenance = source["excludeMaintenance"];
 	    }
 	}
 
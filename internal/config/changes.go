@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Changes descreve, em linguagem curta, o que mudou entre duas configurações. Usado
+// para anotar a linha do tempo.
+func Changes(old, cur AppConfig) []string {
+	var out []string
+
+	before := make(map[string]domain.Host, len(old.Targets))
+	for _, t := range old.Targets {
+		before[t.ID] = t
+	}
+	after := make(map[string]bool, len(cur.Targets))
+	for _, t := range cur.Targets {
+		after[t.ID] = true
+		prev, ok := before[t.ID]
+		if !ok {
+			out = append(out, fmt.Sprintf("alvo %s adicionado (%s)", t.ID, t.IP))
+			continue
+		}
+		// Cada campo é conferido à parte: uma edição pode mudar vários de uma vez
+		if prev.Name != t.Name {
+			out = append(out, fmt.Sprintf("alvo %s: nome %q → %q", t.ID, prev.Name, t.Name))
+		}
+		if prev.IP != t.IP {
+			out = append(out, fmt.Sprintf("alvo %s: IP %s → %s", t.ID, prev.IP, t.IP))
+		}
+		if prev.Active != t.Active {
+			out = append(out, fmt.Sprintf("alvo %s %s", t.ID, activeLabel(t.Active)))
+		}
+		if prev.IsGW != t.IsGW {
+			out = append(out, fmt.Sprintf("alvo %s: gateway %s", t.ID, onOff(t.IsGW)))
+		}
+		if prev.ParentID != t.ParentID {
+			out = append(out, fmt.Sprintf("alvo %s: dependência %q → %q", t.ID, prev.ParentID, t.ParentID))
+		}
+		if !slices.Equal(prev.Tags, t.Tags) {
+			out = append(out, fmt.Sprintf("alvo %s: tags [%s] → [%s]", t.ID,
+				strings.Join(prev.Tags, ", "), strings.Join(t.Tags, ", ")))
+		}
+		if prev.RetentionDays != t.RetentionDays {
+			out = append(out, fmt.Sprintf("alvo %s: retenção %d → %d dias", t.ID, prev.RetentionDays, t.RetentionDays))
+		}
+	}
+	for _, t := range old.Targets {
+		if !after[t.ID] {
+			out = append(out, fmt.Sprintf("alvo %s removido", t.ID))
+		}
+	}
+
+	if old.RetentionDays != cur.RetentionDays {
+		out = append(out, fmt.Sprintf("retenção %d → %d dias", old.RetentionDays, cur.RetentionDays))
+	}
+	sections := []struct {
+		name     string
+		old, cur interface{}
+	}{
+		{"retenção dos agregados", old.RollupDays, cur.RollupDays},
+		{"regras de alerta", old.AlertRules, cur.AlertRules},
+		{"janelas de manutenção", old.Maintenance, cur.Maintenance},
+		{"política de SLA", old.SLA, cur.SLA},
+		{"hooks", old.Hooks, cur.Hooks},
+		{"backup", old.Backup, cur.Backup},
+		{"armazenamento", old.Storage, cur.Storage},
+	}
+	for _, sec := range sections {
+		if !reflect.DeepEqual(sec.old, sec.cur) {
+			out = append(out, sec.name)
+		}
+	}
+	return out
+}
+
+func activeLabel(active bool) string {
+	if active {
+		return "ativado"
+	}
+	return "pausado"
+}
+
+func onOff(on bool) string {
+	if on {
+		return "sim"
+	}
+	return "não"
+}
@@ -5,9 +5,13 @@ import (
 	"lag-monitor/internal/domain"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 )
 
+// Retenção das amostras brutas em um arquivo novo
+const defaultRetentionDays = 7
+
 func GetConfigPath() string {
 	home, _ := os.UserHomeDir()
 	dir := filepath.Join(home, ".config", "lagmon")
@@ -15,7 +19,8 @@ func GetConfigPath() string {
 	return filepath.Join(dir, "settings.json")
 }
 
-// Estrutura do Diagrama (Visualização)
+// Estrutura do Diagrama antigo (três nós fixos). Mantida apenas para migrar
+// arquivos existentes para o grafo de dependências (Host.ParentID).
 type DiagramNode struct {
 	Name string `json:"name"`
 	IP   string `json:"ip"` // O IP é usado para vincular ao Ping
@@ -27,11 +32,52 @@ type NetworkDiagramConfig struct {
 	Internet DiagramNode `json:"internet"`
 }
 
+// HookConfig define um comando executado quando um evento é emitido
+type HookConfig struct {
+	Event      string `json:"event"`       // Nome do evento (ex: "incident:open"), prefixo ("incident:*") ou "*"
+	Command    string `json:"command"`     // Executado via shell do sistema
+	TimeoutSec int    `json:"timeout_sec"` // 0 usa o padrão do runner
+	Enabled    bool   `json:"enabled"`
+}
+
+// RollupRetention define por quantos dias os agregados são mantidos (0 usa o padrão)
+type RollupRetention struct {
+	MinuteDays int `json:"minute_days"`
+	HourDays   int `json:"hour_days"`
+}
+
+// Backends de armazenamento disponíveis
+const (
+	BackendSQLite  = "sqlite"
+	BackendSegment = "segment"
+	BackendMemory  = "memory"
+)
+
+// StorageConfig escolhe onde as amostras são guardadas. Lido apenas na inicialização.
+type StorageConfig struct {
+	Backend       string `json:"backend"`        // "sqlite" (padrão), "segment" ou "memory"
+	Path          string `json:"path"`           // Arquivo (sqlite) ou diretório (segment); vazio usa o padrão
+	MemorySamples int    `json:"memory_samples"` // Amostras por host no backend "memory" (0 = 24h)
+}
+
+// MaintenanceConfig agrupa as janelas de manutenção e como os relatórios as tratam
+type MaintenanceConfig struct {
+	Windows            []domain.MaintenanceWindow `json:"windows"`
+	ExcludeFromReports bool                       `json:"exclude_from_reports"`
+}
+
 // Estrutura Principal do Arquivo
 type AppConfig struct {
-	RetentionDays  int                  `json:"retention_days"`
-	NetworkDiagram NetworkDiagramConfig `json:"network_diagram"`
-	Targets        []domain.Host        `json:"targets"`
+	RetentionDays  int                   `json:"retention_days"`
+	RollupDays     RollupRetention       `json:"rollup_retention"`
+	NetworkDiagram *NetworkDiagramConfig `json:"network_diagram,omitempty"` // Legado, ver migrateDiagram
+	Targets        []domain.Host         `json:"targets"`
+	Hooks          []HookConfig          `json:"hooks"`
+	AlertRules     []domain.AlertRule    `json:"alert_rules"`
+	Maintenance    MaintenanceConfig     `json:"maintenance"`
+	SLA            domain.SLAPolicy      `json:"sla"`
+	Storage        StorageConfig         `json:"storage"`
+	Backup         domain.BackupPolicy   `json:"backup"`
 }
 
 type ConfigManager struct {
@@ -53,15 +99,14 @@ func (c *ConfigManager) Load() error {
 	if os.IsNotExist(err) {
 		// Cria Defaults
 		c.Data = AppConfig{
-			RetentionDays: 7,
-			NetworkDiagram: NetworkDiagramConfig{
-				Local:    DiagramNode{Name: "You (Local)", IP: "127.0.0.1"},
-				Gateway:  DiagramNode{Name: "Gateway", IP: "192.168.1.1"},
-				Internet: DiagramNode{Name: "Internet", IP: "8.8.8.8"},
-			},
+			RetentionDays: defaultRetentionDays,
+			RollupDays:    RollupRetention{MinuteDays: 90, HourDays: 730},
+			SLA:           domain.SLAPolicy{Definition: domain.SLALoss, LatencyThresholdMs: 150, TargetPct: 99},
+			Storage:       StorageConfig{Backend: BackendSQLite},
+			Backup:        domain.BackupPolicy{Enabled: true, IntervalHours: 24, Keep: 7},
 			Targets: []domain.Host{
 				{ID: "gateway", Name: "Gateway", IP: "192.168.1.1", IsGW: true, Active: true},
-				{ID: "google", Name: "Google DNS", IP: "8.8.8.8", IsGW: false, Active: true},
+				{ID: "google", Name: "Google DNS", IP: "8.8.8.8", IsGW: false, Active: true, ParentID: "gateway"},
 			},
 		}
 		return c.Save() // Cria o arquivo físico
@@ -69,7 +114,69 @@ func (c *ConfigManager) Load() error {
 		return err
 	}
 
-	return json.Unmarshal(file, &c.Data)
+	if err := json.Unmarshal(file, &c.Data); err != nil {
+		return err
+	}
+	c.Data.normalizeTags()
+	if c.migrateDiagram() {
+		return c.Save()
+	}
+	return nil
+}
+
+// normalizeTags aplica aos alvos a mesma limpeza de SetTargetTags (arquivo editado à mão)
+func (cfg *AppConfig) normalizeTags() {
+	for i := range cfg.Targets {
+		cfg.Targets[i].Tags = domain.NormalizeTags(cfg.Targets[i].Tags)
+	}
+}
+
+// migrateDiagram converte o diagrama fixo local→gateway→internet em dependências
+// entre os alvos. Retorna true se o arquivo precisa ser regravado.
+func (c *ConfigManager) migrateDiagram() bool {
+	d := c.Data.NetworkDiagram
+	if d == nil {
+		return false
+	}
+	c.Data.NetworkDiagram = nil
+
+	for _, t := range c.Data.Targets {
+		if t.ParentID != "" {
+			return true // Já existe um grafo definido pelo usuário
+		}
+	}
+
+	var localID, gatewayID string
+	for i, t := range c.Data.Targets {
+		if t.IP == d.Local.IP {
+			localID = t.ID
+		}
+		if t.IP == d.Gateway.IP {
+			gatewayID = t.ID
+			c.Data.Targets[i].IsGW = true
+		}
+	}
+	if gatewayID == "" {
+		for _, t := range c.Data.Targets {
+			if t.IsGW {
+				gatewayID = t.ID
+				break
+			}
+		}
+	}
+
+	for i, t := range c.Data.Targets {
+		switch {
+		case t.ID == localID:
+		case t.ID == gatewayID:
+			c.Data.Targets[i].ParentID = localID
+		case gatewayID != "":
+			c.Data.Targets[i].ParentID = gatewayID
+		default:
+			c.Data.Targets[i].ParentID = localID
+		}
+	}
+	return true
 }
 
 func (c *ConfigManager) Save() error {
@@ -127,10 +234,14 @@ func (c *ConfigManager) UpdateTargetStatus(id string, active bool) error {
 	return c.Save()
 }
 
-func (c *ConfigManager) UpdateConfig(newCfg AppConfig) {
+// UpdateConfig substitui a configuração e retorna o que mudou (ver Changes)
+func (c *ConfigManager) UpdateConfig(newCfg AppConfig) []string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	newCfg.normalizeTags()
+	changes := Changes(c.Data, newCfg)
 	c.Data = newCfg
+	return changes
 }
 
 func (c *ConfigManager) UpdateTargetDiagramVisibility(id string, show bool) error {
@@ -145,3 +256,119 @@ func (c *ConfigManager) UpdateTargetDiagramVisibility(id string, show bool) erro
 	}
 	return c.Save()
 }
+
+// GetHooks retorna uma cópia dos hooks configurados (seguro para uso concorrente)
+func (c *ConfigManager) GetHooks() []HookConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hooks := make([]HookConfig, len(c.Data.Hooks))
+	copy(hooks, c.Data.Hooks)
+	return hooks
+}
+
+// GetSLA retorna a política de SLA configurada (seguro para uso concorrente)
+func (c *ConfigManager) GetSLA() domain.SLAPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Data.SLA
+}
+
+func (c *ConfigManager) UpdateTargetParent(id, parentID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, t := range c.Data.Targets {
+		if t.ID == id {
+			c.Data.Targets[i].ParentID = parentID
+			break
+		}
+	}
+	return c.Save()
+}
+
+func (c *ConfigManager) UpdateTargetTags(id string, tags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, t := range c.Data.Targets {
+		if t.ID == id {
+			c.Data.Targets[i].Tags = domain.NormalizeTags(tags)
+			break
+		}
+	}
+	return c.Save()
+}
+
+func (c *ConfigManager) UpdateTargetRetention(id string, days int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, t := range c.Data.Targets {
+		if t.ID == id {
+			c.Data.Targets[i].RetentionDays = days
+			break
+		}
+	}
+	return c.Save()
+}
+
+// ImportLegacySettings traz as preferências que ficavam na tabela settings do banco. O
+// valor do settings.json prevalece, exceto quando ainda é o padrão.
+func (c *ConfigManager) ImportLegacySettings(kv map[string]string) error {
+	days, err := strconv.Atoi(kv["retention_days"])
+	if err != nil || days <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Data.RetentionDays != 0 && c.Data.RetentionDays != defaultRetentionDays {
+		return nil
+	}
+	c.Data.RetentionDays = days
+	return c.Save()
+}
+
+// GetMaintenance retorna uma cópia da configuração de manutenção (seguro para uso concorrente)
+func (c *ConfigManager) GetMaintenance() MaintenanceConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.Data.Maintenance
+	m.Windows = make([]domain.MaintenanceWindow, len(c.Data.Maintenance.Windows))
+	copy(m.Windows, c.Data.Maintenance.Windows)
+	return m
+}
+
+func (c *ConfigManager) AddMaintenanceWindow(w domain.MaintenanceWindow) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Data.Maintenance.Windows = append(c.Data.Maintenance.Windows, w)
+	return c.Save()
+}
+
+func (c *ConfigManager) RemoveMaintenanceWindow(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	windows := []domain.MaintenanceWindow{}
+	for _, w := range c.Data.Maintenance.Windows {
+		if w.ID != id {
+			windows = append(windows, w)
+		}
+	}
+	c.Data.Maintenance.Windows = windows
+	return c.Save()
+}
+
+// Retention monta a política de retenção de todos os níveis
+func (cfg AppConfig) Retention() domain.RetentionPolicy {
+	return domain.RetentionPolicy{
+		RawDays:    cfg.RetentionDays,
+		MinuteDays: cfg.RollupDays.MinuteDays,
+		HourDays:   cfg.RollupDays.HourDays,
+	}
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Agregações calculadas em Go, com o mesmo resultado das consultas do SQLite. Usadas
+// pelos repositórios que não têm um motor de consultas (memória, segmentos).
+
+// BucketStart alinha o instante ao início do intervalo, contando a partir do epoch
+// (mesmo alinhamento dos agregados e das séries do SQLite)
+func BucketStart(t time.Time, bucket time.Duration) time.Time {
+	size := bucket.Microseconds()
+	if size <= 0 {
+		return t
+	}
+	return time.UnixMicro(t.UnixMicro() / size * size)
+}
+
+// Series agrupa amostras em ordem cronológica em intervalos de tamanho bucket.
+// bucket <= 0 resume todas as amostras em um único ponto iniciado em start.
+func Series(hostID string, samples []PingResult, start time.Time, bucket time.Duration) []Rollup {
+	var out []Rollup
+	var group []PingResult
+	var current time.Time
+
+	flush := func() {
+		if len(group) > 0 {
+			out = append(out, BuildRollup(hostID, ResolutionRaw, current, group))
+		}
+		group = group[:0]
+	}
+
+	for _, s := range samples {
+		b := start
+		if bucket > 0 {
+			b = BucketStart(s.Timestamp, bucket)
+		}
+		if len(group) > 0 && !b.Equal(current) {
+			flush()
+		}
+		current = b
+		group = append(group, s)
+	}
+	flush()
+	return out
+}
+
+// MergeRollups reagrupa agregados em intervalos maiores. Médias são ponderadas pelos
+// pacotes respondidos; P95/P99 usam o maior valor (limite superior).
+func MergeRollups(hostID string, res Resolution, rollups []Rollup, start time.Time, bucket time.Duration) []Rollup {
+	type acc struct {
+		r                      Rollup
+		ok                     int64
+		latSum, p50Sum, jitSum int64
+	}
+
+	var out []Rollup
+	var cur *acc
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		if cur.ok > 0 {
+			cur.r.LatAvg = cur.latSum / cur.ok
+			cur.r.LatP50 = cur.p50Sum / cur.ok
+			cur.r.JitterAvg = cur.jitSum / cur.ok
+		}
+		out = append(out, cur.r)
+		cur = nil
+	}
+
+	for _, r := range rollups {
+		b := start
+		if bucket > 0 {
+			b = BucketStart(r.Bucket, bucket)
+		}
+		if cur != nil && !cur.r.Bucket.Equal(b) {
+			flush()
+		}
+		if cur == nil {
+			cur = &acc{r: Rollup{HostID: hostID, Resolution: res, Bucket: b}}
+		}
+
+		ok := int64(r.Count - r.LossCount)
+		if ok > 0 {
+			if cur.ok == 0 || r.LatMin < cur.r.LatMin {
+				cur.r.LatMin = r.LatMin
+			}
+			cur.r.LatMax = max(cur.r.LatMax, r.LatMax)
+			cur.r.LatP95 = max(cur.r.LatP95, r.LatP95)
+			cur.r.LatP99 = max(cur.r.LatP99, r.LatP99)
+			cur.r.JitterMax = max(cur.r.JitterMax, r.JitterMax)
+			cur.ok += ok
+			cur.latSum += r.LatAvg * ok
+			cur.p50Sum += r.LatP50 * ok
+			cur.jitSum += r.JitterAvg * ok
+		}
+		cur.r.Count += r.Count
+		cur.r.LossCount += r.LossCount
+	}
+	flush()
+	return out
+}
+
+// Histogram distribui as latências respondidas em faixas de binWidth microsegundos
+func Histogram(samples []PingResult, binWidth int64) []HistogramBin {
+	counts := make(map[int64]int)
+	for _, s := range samples {
+		if !s.Loss {
+			counts[s.Latency/binWidth]++
+		}
+	}
+
+	out := make([]HistogramBin, 0, len(counts))
+	for bin, n := range counts {
+		out = append(out, HistogramBin{From: bin * binWidth, To: (bin + 1) * binWidth, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].From < out[j].From })
+	return out
+}
+
+// RangeStatsOf resume as amostras do período em um único ponto
+func RangeStatsOf(hostID string, samples []PingResult, start, end time.Time) RangeStats {
+	points := Series(hostID, samples, start, 0)
+	if len(points) == 0 {
+		return RangeStats{HostID: hostID, Resolution: ResolutionRaw, Start: start, End: end}
+	}
+	return StatsFromRollup(points[0], end)
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
 
 // PingResult representa um único ponto de dados
 type PingResult struct {
@@ -14,22 +19,351 @@ type PingResult struct {
 
 // Host define um alvo para monitoramento
 type Host struct {
-	ID            string `json:"id"`
-	Name          string `json:"name"`
-	IP            string `json:"ip"`
-	IsGW          bool   `json:"isGateway"`
-	Active        bool   `json:"active"`
-	ShowInDiagram bool   `json:"showInDiagram"` // Novo campo
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	IP            string   `json:"ip"`
+	IsGW          bool     `json:"isGateway"`
+	Active        bool     `json:"active"`
+	ShowInDiagram bool     `json:"showInDiagram"`           // Novo campo
+	ParentID      string   `json:"parentId,omitempty"`      // Host do qual este depende (ex: gateway)
+	Tags          []string `json:"tags,omitempty"`          // Grupos do host (ex: "gaming", "dns")
+	RetentionDays int      `json:"retentionDays,omitempty"` // Sobrescreve retention_days nas amostras brutas deste host (0 usa o global)
 }
 
-// Repository define como salvamos os dados
+// NormalizeTags remove espaços, vazios e duplicados
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+// GroupStats agrega as amostras de todos os hosts de um grupo (tag)
+type GroupStats struct {
+	Group      string  `json:"group"`
+	Hosts      int     `json:"hosts"`
+	Down       int     `json:"down"` // Hosts com incidente aberto (apenas ao vivo)
+	Samples    int     `json:"samples"`
+	AvgLatency int64   `json:"avgLatency"` // em microsegundos
+	MinLatency int64   `json:"minLatency"`
+	MaxLatency int64   `json:"maxLatency"`
+	AvgJitter  int64   `json:"avgJitter"`
+	LossPct    float64 `json:"lossPct"`
+}
+
+// AlertRule dispara um alerta quando as estatísticas ao vivo de um grupo ultrapassam os limites
+type AlertRule struct {
+	Group        string  `json:"group"`
+	MaxLatencyMs int64   `json:"max_latency_ms"` // 0 desativa o critério
+	MaxLossPct   float64 `json:"max_loss_pct"`   // 0 desativa o critério
+	MinDown      int     `json:"min_down"`       // Alerta com N ou mais hosts fora do ar; 0 desativa
+	Enabled      bool    `json:"enabled"`
+}
+
+// GroupAlert é emitido quando uma regra de grupo é violada ou volta ao normal
+type GroupAlert struct {
+	Rule      AlertRule  `json:"rule"`
+	Stats     GroupStats `json:"stats"`
+	Reason    string     `json:"reason"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// Estados de um nó na topologia
+const (
+	StatusUp      = "up"
+	StatusDown    = "down"
+	StatusPaused  = "paused"
+	StatusUnknown = "unknown"
+)
+
+// TopologyNode é um host no grafo de dependências com seu estado ao vivo
+type TopologyNode struct {
+	Host
+	Status     string `json:"status"`
+	Latency    int64  `json:"latency"`    // em microsegundos, última amostra
+	Suppressed bool   `json:"suppressed"` // Fora do ar porque um ancestral também está
+}
+
+// RootCause é a origem provável de uma perda, deduzida da cadeia de dependências do host
+type RootCause string
+
+const (
+	CauseUnknown  RootCause = ""
+	CauseLAN      RootCause = "LAN/Wi-Fi problem"
+	CauseGateway  RootCause = "gateway/modem problem"
+	CauseUpstream RootCause = "ISP/upstream problem"
+)
+
+// RootCauseEvent é emitido quando uma perda em um alvo de internet é classificada
+type RootCauseEvent struct {
+	HostID     string    `json:"hostId"`
+	Cause      RootCause `json:"cause"`
+	FailedNode string    `json:"failedNode"` // Nó mais próximo da raiz que também perdeu pacotes
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// Incident representa uma sequência contínua de perdas em um host
+type Incident struct {
+	HostID      string     `json:"hostId"`
+	IP          string     `json:"ip"`
+	Start       time.Time  `json:"start"`
+	End         *time.Time `json:"end,omitempty"` // nil enquanto o incidente estiver aberto
+	LostPackets int        `json:"lostPackets"`
+	Cause       RootCause  `json:"cause,omitempty"`
+	Suppressed  bool       `json:"suppressed"` // Aberto enquanto um ancestral estava fora do ar
+	Planned     bool       `json:"planned"`    // Aberto durante uma janela de manutenção
+}
+
+// MaintenanceWindow é um período planejado em que incidentes são registrados como
+// previstos e alertas ficam mudos. Sem HostID e sem Group vale para todos os hosts.
+type MaintenanceWindow struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	HostID      string     `json:"host_id,omitempty"`
+	Group       string     `json:"group,omitempty"`
+	Start       *time.Time `json:"start,omitempty"` // Janela única
+	End         *time.Time `json:"end,omitempty"`
+	Cron        string     `json:"cron,omitempty"` // Janela recorrente (ex: "0 4 * * *")
+	DurationMin int        `json:"duration_min,omitempty"`
+}
+
+// Anomaly é emitido quando uma janela de amostras foge do comportamento aprendido do host
+type Anomaly struct {
+	HostID    string    `json:"hostId"`
+	Metric    string    `json:"metric"` // "latency" (ms) ou "loss" (%)
+	Value     float64   `json:"value"`
+	Expected  float64   `json:"expected"`
+	StdDev    float64   `json:"stdDev"`
+	Score     float64   `json:"score"` // Desvios-padrão acima do esperado
+	Window    time.Time `json:"window"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BaselineSlot é o comportamento aprendido de um host em uma hora da semana
+type BaselineSlot struct {
+	HourOfWeek int     `json:"hourOfWeek"` // 0 = domingo 00h
+	LatencyMs  float64 `json:"latencyMs"`
+	LatencyDev float64 `json:"latencyDev"`
+	LossPct    float64 `json:"lossPct"`
+	LossDev    float64 `json:"lossDev"`
+	Minutes    int     `json:"minutes"` // Minutos observados
+}
+
+// Definições de disponibilidade aceitas em SLAPolicy
+const (
+	SLALoss     = "loss"     // Cada pacote perdido conta como indisponibilidade
+	SLAIncident = "incident" // Só conta como indisponível o tempo dentro de quedas (perdas consecutivas)
+	SLALatency  = "latency"  // Perdas e respostas acima do limite de latência contam como indisponíveis
+)
+
+// SLAPolicy define como a disponibilidade é calculada
+type SLAPolicy struct {
+	Definition         string  `json:"definition"`           // SLALoss, SLAIncident ou SLALatency
+	LatencyThresholdMs int64   `json:"latency_threshold_ms"` // Usado apenas por SLALatency
+	TargetPct          float64 `json:"target_pct"`           // Meta contratada (ex: 99.5); 0 sem meta
+}
+
+// SLAReport resume a disponibilidade de um host em um período
+type SLAReport struct {
+	HostID       string    `json:"hostId"`
+	Definition   string    `json:"definition"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Samples      int       `json:"samples"`
+	Availability float64   `json:"availability"` // em %
+	Outages      int       `json:"outages"`
+	DowntimeSec  float64   `json:"downtimeSec"`
+	MTBFSec      float64   `json:"mtbfSec"` // Tempo médio entre quedas (0 sem quedas)
+	MTTRSec      float64   `json:"mttrSec"` // Tempo médio de recuperação (0 sem quedas)
+	TargetPct    float64   `json:"targetPct"`
+	MetTarget    bool      `json:"metTarget"`
+	Estimated    bool      `json:"estimated,omitempty"` // Parte do período veio dos agregados por minuto
+}
+
+// RetentionPolicy define por quantos dias cada nível de armazenamento é mantido
+type RetentionPolicy struct {
+	RawDays    int `json:"raw_days"`
+	MinuteDays int `json:"minute_days"`
+	HourDays   int `json:"hour_days"`
+}
+
+// DefaultRetention é usada para níveis não configurados
+var DefaultRetention = RetentionPolicy{RawDays: 7, MinuteDays: 90, HourDays: 730}
+
+// CleanupReport resume uma execução da política de retenção
+type CleanupReport struct {
+	RawDeleted     int64     `json:"rawDeleted"`
+	MinuteDeleted  int64     `json:"minuteDeleted"`
+	HourDeleted    int64     `json:"hourDeleted"`
+	BytesReclaimed int64     `json:"bytesReclaimed"` // Espaço devolvido ao sistema pelo VACUUM incremental
+	DurationMs     int64     `json:"durationMs"`
+	Error          string    `json:"error,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// Snapshot é uma cópia consistente do banco na pasta de backups
+type Snapshot struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BackupPolicy define os backups automáticos
+type BackupPolicy struct {
+	Enabled       bool `json:"enabled"`
+	IntervalHours int  `json:"interval_hours"` // 0 usa 24h
+	Keep          int  `json:"keep"`           // Snapshots mantidos na rotação (0 mantém todos)
+}
+
+// StorageHealth descreve o estado do caminho de escrita do repositório
+type StorageHealth struct {
+	Healthy             bool       `json:"healthy"`
+	Buffered            int        `json:"buffered"` // Amostras aceitas aguardando gravação
+	Capacity            int        `json:"capacity"` // Limite do buffer antes de descartar
+	Dropped             int64      `json:"dropped"`  // Amostras descartadas desde o início
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	LastErrorAt         *time.Time `json:"lastErrorAt,omitempty"`
+	LastFlushAt         *time.Time `json:"lastFlushAt,omitempty"`
+}
+
+// StorageError é emitido quando uma gravação falha ou amostras são descartadas. Descartes
+// são avisados ao começar, no máximo uma vez por minuto enquanto durarem e ao terminar.
+type StorageError struct {
+	Op        string    `json:"op"` // "flush", "drop", "recover" (buffer voltou a aceitar tudo) ou "close"
+	Message   string    `json:"message"`
+	Samples   int       `json:"samples"` // Amostras afetadas
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Origem de uma anotação
+const (
+	AnnotationUser    = "user"    // Criada pelo usuário
+	AnnotationNetwork = "network" // Mudança detectada na rede local
+	AnnotationConfig  = "config"  // Alteração na configuração do app
+)
+
+// Annotation é uma nota livre na linha do tempo ("liguei para o provedor", "troquei o
+// roteador"), em um instante ou em um intervalo
+type Annotation struct {
+	ID        int64      `json:"id"`
+	HostID    string     `json:"hostId,omitempty"` // Vazio vale para todos os hosts
+	Start     time.Time  `json:"start"`
+	End       *time.Time `json:"end,omitempty"` // Ausente para um instante
+	Text      string     `json:"text"`
+	Kind      string     `json:"kind"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Until retorna o fim da anotação (o próprio início, para um instante)
+func (a Annotation) Until() time.Time {
+	if a.End != nil {
+		return *a.End
+	}
+	return a.Start
+}
+
+// FilterAnnotations seleciona as anotações que tocam o período (e valem para o host,
+// quando informado), em ordem cronológica. Usado pelos repositórios sem motor de consultas.
+func FilterAnnotations(all []Annotation, hostID string, start, end time.Time) []Annotation {
+	out := []Annotation{}
+	for _, a := range all {
+		if a.Start.After(end) || a.Until().Before(start) {
+			continue
+		}
+		if hostID != "" && a.HostID != "" && a.HostID != hostID {
+			continue
+		}
+		out = append(out, a)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// ImportReport resume a importação de um arquivo de histórico
+type ImportReport struct {
+	Format     string   `json:"format"`
+	HostIDs    []string `json:"hostIds"`    // Hosts encontrados no arquivo
+	Read       int      `json:"read"`       // Amostras lidas
+	Imported   int      `json:"imported"`   // Amostras gravadas
+	Duplicates int      `json:"duplicates"` // Amostras que já existiam no repositório
+	Invalid    int      `json:"invalid"`    // Linhas que não puderam ser interpretadas
+	DurationMs int64    `json:"durationMs"`
+}
+
+// ErrBufferFull indica que amostras antigas foram descartadas para aceitar novas
+var ErrBufferFull = errors.New("buffer de gravação cheio")
+
+// ErrStorageClosed indica que o repositório já foi fechado
+var ErrStorageClosed = errors.New("armazenamento fechado")
+
+// ErrAnnotationNotFound indica que não há anotação com o ID informado
+var ErrAnnotationNotFound = errors.New("anotação não encontrada")
+
+// Repository define como salvamos e consultamos as séries de ping
 type Repository interface {
 	SaveBatch(results []PingResult) error
 	Close() error
 	GetHistory(hostID string, start, end time.Time) ([]PingResult, error)
-	CleanOldData(days int) (int64, error)
-	SetSetting(key, value string) error
-	GetSetting(key string) (string, error)
+	GetRollups(hostID string, res Resolution, start, end time.Time) ([]Rollup, error)
+	GetStats(hostID string, res Resolution, start, end time.Time) (RangeStats, error)
+	GetSeries(hostID string, res Resolution, start, end time.Time, bucket time.Duration) ([]Rollup, error)
+	GetHistogram(hostID string, start, end time.Time, binWidth int64) ([]HistogramBin, error)
+	CleanOldData(days int, overrides map[string]int) (int64, error) // overrides: dias por host
+	CleanOldRollups(res Resolution, days int) (int64, error)
+	Compact() (int64, error) // Devolve o espaço livre ao sistema; retorna os bytes recuperados
+}
+
+// Snapshotter é implementado por repositórios que suportam backup e restauração
+type Snapshotter interface {
+	CreateSnapshot() (Snapshot, error)
+	ListSnapshots() ([]Snapshot, error)
+	RotateSnapshots(keep int) (int, error)
+	RestoreSnapshot(name string) error // Aplicada na próxima inicialização
+}
+
+// Importer é implementado por repositórios que aceitam amostras fora de ordem (histórico
+// vindo de outra máquina). Uma amostra é duplicada quando o host já tem outra em
+// [ts, ts+precision); retorna quantas foram gravadas.
+type Importer interface {
+	Import(results []PingResult, precision time.Duration) (int, error)
+}
+
+// AnnotationStore é implementado por repositórios que guardam anotações
+type AnnotationStore interface {
+	AddAnnotation(a Annotation) (Annotation, error)
+	// ListAnnotations retorna as anotações que tocam o período, em ordem cronológica.
+	// Com hostID, inclui também as anotações globais.
+	ListAnnotations(hostID string, start, end time.Time) ([]Annotation, error)
+	DeleteAnnotation(id int64) error
+}
+
+// NetworkProbe descreve a rede local: endereços de cada interface ativa
+type NetworkProbe interface {
+	LocalAddresses() (map[string][]string, error)
+}
+
+// RouteProbe é implementado por probes que também conhecem o gateway padrão
+type RouteProbe interface {
+	DefaultGateway() (string, error)
+}
+
+// ErrorNotifier é implementado por repositórios que avisam falhas de gravação
+type ErrorNotifier interface {
+	OnError(fn func(StorageError))
+}
+
+// HealthReporter é implementado por repositórios que expõem o estado da escrita
+type HealthReporter interface {
+	Health() StorageHealth
 }
 
 // Pinger define como executamos o ping
@@ -0,0 +1,111 @@
+package domain
+
+import "time"
+
+// ReportData reúne, já calculado, tudo o que um relatório de host apresenta. Os
+// formatos (HTML, texto...) apenas desenham estes dados.
+type ReportData struct {
+	HostID      string     `json:"hostId"`
+	HostName    string     `json:"hostName,omitempty"`
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	GeneratedAt time.Time  `json:"generatedAt"`
+	Resolution  Resolution `json:"resolution"` // Nível de onde os indicadores vieram
+	Stats       RangeStats `json:"stats"`
+	Status      string     `json:"status"` // Veredito: EXCELENTE, INSTÁVEL ou CRÍTICO / RUIM
+
+	// Latência normal aprendida para o horário do período (ms), quando conhecida
+	NormalLatMs    float64 `json:"normalLatMs,omitempty"`
+	NormalStdMs    float64 `json:"normalStdMs,omitempty"`
+	PlannedSamples int     `json:"plannedSamples,omitempty"` // Desconsideradas por manutenção (nos agregados, o intervalo inteiro que toca a janela)
+	AnomalyMinutes int     `json:"anomalyMinutes,omitempty"`
+
+	// Das amostras brutas enquanto existem e, antes disso, dos agregados por minuto (SLA
+	// estimado); o histograma só quando todo o período tem amostras brutas
+
+	SLA       *SLAReport        `json:"sla,omitempty"`
+	Causes    map[RootCause]int `json:"causes,omitempty"`
+	Incidents []Incident        `json:"incidents"`
+	Histogram []HistogramBin    `json:"histogram,omitempty"`
+
+	Series      HostSeries   `json:"series"`
+	Heatmap     []HeatCell   `json:"heatmap"` // Dia da semana × hora
+	Annotations []Annotation `json:"annotations"`
+}
+
+// HeatCell resume um horário da semana (dia × hora, no fuso local) no período
+type HeatCell struct {
+	Weekday time.Weekday `json:"weekday"`
+	Hour    int          `json:"hour"`
+	Count   int          `json:"count"`
+	LatAvg  int64        `json:"latAvg"` // Microsegundos, só pacotes respondidos
+	LossPct float64      `json:"lossPct"`
+}
+
+// ComparisonData reúne os relatórios de vários hosts no mesmo período, para compará-los
+// lado a lado e cruzar as perdas
+type ComparisonData struct {
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	GeneratedAt time.Time    `json:"generatedAt"`
+	Hosts       []ReportData `json:"hosts"`
+
+	// Overlap[i][j]: % dos intervalos com perda do host i em que o host j também perdeu
+	Overlap     [][]float64  `json:"overlap"`
+	LossPeriods []LossPeriod `json:"lossPeriods"` // Perdas simultâneas em dois ou mais hosts
+}
+
+// LossPeriod é um trecho contínuo em que o mesmo conjunto de hosts perdeu pacotes
+type LossPeriod struct {
+	Start   time.Time          `json:"start"`
+	End     time.Time          `json:"end"`
+	HostIDs []string           `json:"hostIds"`
+	PeakPct map[string]float64 `json:"peakPct"` // Maior perda de cada host em um intervalo do trecho
+}
+
+// Nível de significância dos testes da comparação entre períodos
+const SignificanceLevel = 0.05
+
+// PeriodComparison compara o mesmo host em dois períodos (ex.: esta semana × a anterior,
+// antes × depois da troca do roteador)
+type PeriodComparison struct {
+	HostID      string        `json:"hostId"`
+	HostName    string        `json:"hostName,omitempty"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Base        ReportData    `json:"base"`    // Período de referência
+	Current     ReportData    `json:"current"` // Período comparado
+	BucketSec   int64         `json:"bucketSec"`
+	Deltas      []MetricDelta `json:"deltas"`
+}
+
+// MetricDelta é a variação de um indicador entre os dois períodos. Em todos os
+// indicadores, valores menores são melhores.
+type MetricDelta struct {
+	Metric   string  `json:"metric"` // latAvg, latP95, jitterAvg, lossPct ou incidentsPerDay
+	Label    string  `json:"label"`
+	Unit     string  `json:"unit"` // ms, % ou /dia
+	Base     float64 `json:"base"`
+	Current  float64 `json:"current"`
+	Delta    float64 `json:"delta"`    // Current - Base
+	DeltaPct float64 `json:"deltaPct"` // Relativa a Base (0 quando Base é 0)
+	Test     string  `json:"test"`     // Teste de hipótese aplicado
+	PValue   float64 `json:"pValue"`   // -1 sem dados suficientes para o teste
+}
+
+// Significant indica se a diferença é estatisticamente significativa
+func (d MetricDelta) Significant() bool {
+	return d.PValue >= 0 && d.PValue < SignificanceLevel
+}
+
+// Verdict resume a variação: melhorou, piorou ou sem diferença significativa
+func (d MetricDelta) Verdict() string {
+	switch {
+	case d.PValue < 0:
+		return "dados insuficientes"
+	case !d.Significant():
+		return "sem diferença significativa"
+	case d.Delta < 0:
+		return "melhorou"
+	}
+	return "piorou"
+}
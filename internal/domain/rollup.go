@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Resolution é a granularidade de uma série armazenada
+type Resolution string
+
+const (
+	ResolutionRaw    Resolution = "raw"
+	ResolutionMinute Resolution = "1m"
+	ResolutionHour   Resolution = "1h"
+)
+
+// Duration retorna o tamanho do intervalo de cada ponto (0 para amostras brutas)
+func (r Resolution) Duration() time.Duration {
+	switch r {
+	case ResolutionMinute:
+		return time.Minute
+	case ResolutionHour:
+		return time.Hour
+	}
+	return 0
+}
+
+// Rollup resume as amostras de um host em um intervalo (minuto ou hora)
+type Rollup struct {
+	HostID     string     `json:"hostId"`
+	Resolution Resolution `json:"resolution"`
+	Bucket     time.Time  `json:"bucket"` // Início do intervalo
+	Count      int        `json:"count"`
+	LossCount  int        `json:"lossCount"`
+	LatMin     int64      `json:"latMin"` // Latências em microsegundos, só pacotes respondidos
+	LatAvg     int64      `json:"latAvg"`
+	LatMax     int64      `json:"latMax"`
+	LatP50     int64      `json:"latP50"`
+	LatP95     int64      `json:"latP95"`
+	LatP99     int64      `json:"latP99"`
+	JitterAvg  int64      `json:"jitterAvg"`
+	JitterMax  int64      `json:"jitterMax"`
+}
+
+// BuildRollup resume as amostras de um intervalo
+func BuildRollup(hostID string, res Resolution, bucket time.Time, samples []PingResult) Rollup {
+	r := Rollup{HostID: hostID, Resolution: res, Bucket: bucket, Count: len(samples)}
+
+	lats := make([]int64, 0, len(samples))
+	var latSum, jitSum int64
+	for _, s := range samples {
+		if s.Loss {
+			r.LossCount++
+			continue
+		}
+		lats = append(lats, s.Latency)
+		latSum += s.Latency
+		jitSum += s.Jitter
+		if s.Jitter > r.JitterMax {
+			r.JitterMax = s.Jitter
+		}
+	}
+	if len(lats) == 0 {
+		return r
+	}
+
+	sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+	r.LatMin, r.LatMax = lats[0], lats[len(lats)-1]
+	r.LatAvg = latSum / int64(len(lats))
+	r.JitterAvg = jitSum / int64(len(lats))
+	r.LatP50 = Percentile(lats, 50)
+	r.LatP95 = Percentile(lats, 95)
+	r.LatP99 = Percentile(lats, 99)
+	return r
+}
+
+// Percentile calcula o percentil p (0-100) de valores já ordenados pelo método do rank mais próximo
+func Percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"math"
+	"sort"
+)
+
+// Testes de hipótese usados na comparação entre períodos. Todos retornam o p-valor
+// bilateral, ou -1 quando não há dados suficientes para o teste.
+
+// MannWhitney compara duas amostras sem supor distribuição normal (latências são
+// assimétricas), pela aproximação normal com correção de empates
+func MannWhitney(a, b []float64) float64 {
+	n1, n2 := len(a), len(b)
+	if n1 < 3 || n2 < 3 {
+		return -1
+	}
+
+	type obs struct {
+		v     float64
+		first bool
+	}
+	all := make([]obs, 0, n1+n2)
+	for _, v := range a {
+		all = append(all, obs{v, true})
+	}
+	for _, v := range b {
+		all = append(all, obs{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Postos médios nos empates
+	var r1, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				r1 += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n := float64(n1 + n2)
+	u := r1 - float64(n1)*float64(n1+1)/2
+	mu := float64(n1) * float64(n2) / 2
+	sigma := math.Sqrt(float64(n1) * float64(n2) / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+	return normalP(math.Max(0, math.Abs(u-mu)-0.5) / sigma)
+}
+
+// PoissonRates compara k1 eventos em t1 com k2 eventos em t2 (ex.: incidentes por hora).
+// Condicionado ao total, k1 segue uma binomial; o teste é exato.
+func PoissonRates(k1 int, t1 float64, k2 int, t2 float64) float64 {
+	if t1 <= 0 || t2 <= 0 {
+		return -1
+	}
+	n := k1 + k2
+	if n == 0 {
+		return 1
+	}
+	p := t1 / (t1 + t2)
+
+	// Soma as probabilidades dos resultados tão ou menos prováveis que o observado
+	observed := binomialPMF(k1, n, p)
+	sum := 0.0
+	for k := 0; k <= n; k++ {
+		if pk := binomialPMF(k, n, p); pk <= observed*(1+1e-7) {
+			sum += pk
+		}
+	}
+	return math.Min(sum, 1)
+}
+
+func binomialPMF(k, n int, p float64) float64 {
+	lg := func(x int) float64 { v, _ := math.Lgamma(float64(x + 1)); return v }
+	return math.Exp(lg(n) - lg(k) - lg(n-k) + float64(k)*math.Log(p) + float64(n-k)*math.Log1p(-p))
+}
+
+// normalP é o p-valor bilateral de um escore z
+func normalP(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
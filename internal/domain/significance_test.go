@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func seq(from, to float64) []float64 {
+	var out []float64
+	for v := from; v <= to; v++ {
+		out = append(out, v)
+	}
+	return out
+}
+
+func TestMannWhitney(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{"poucas amostras", []float64{1, 2}, []float64{3, 4, 5}, -1},
+		{"sem amostras", nil, seq(1, 10), -1},
+		{"todos empatados", []float64{5, 5, 5}, []float64{5, 5, 5, 5}, 1},
+		{"intercaladas", []float64{1, 3, 5, 7, 9}, []float64{2, 4, 6, 8, 10}, 0.6761033140231468},
+		{"separadas", seq(1, 10), seq(11, 20), 0.0001826717911095504},
+		{"iguais", seq(1, 10), seq(1, 10), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MannWhitney(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("p = %v, esperado %v", got, tt.want)
+			}
+			// O teste é bilateral: a ordem dos períodos não muda o p-valor
+			if got := MannWhitney(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("invertido: p = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMannWhitneyTies(t *testing.T) {
+	// Perdas por intervalo: a maioria dos intervalos sem perda, como na comparação real
+	quiet := []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1.7, 0, 0, 0, 0, 0, 0, 0}
+	noisy := []float64{0, 3.3, 0, 5, 0, 1.7, 8.3, 0, 0, 6.7, 0, 3.3, 0, 0, 10, 0, 1.7, 0, 5, 0}
+
+	p := MannWhitney(quiet, noisy)
+	if p < 0 || p >= SignificanceLevel {
+		t.Errorf("p = %v, esperado significativo", p)
+	}
+	if p := MannWhitney(noisy, noisy[:10]); p < SignificanceLevel {
+		t.Errorf("mesma série: p = %v, esperado não significativo", p)
+	}
+}
+
+func TestPoissonRates(t *testing.T) {
+	tests := []struct {
+		name   string
+		k1     int
+		t1     float64
+		k2     int
+		t2     float64
+		want   float64
+		signif bool
+	}{
+		{"período vazio", 1, 0, 1, 1, -1, false},
+		{"duração negativa", 1, 1, 1, -1, -1, false},
+		{"nenhum evento", 0, 7, 0, 7, 1, false},
+		{"taxas iguais", 4, 7, 4, 7, 1, false},
+		{"taxas iguais em durações diferentes", 2, 1, 6, 3, 1, false},
+		{"3 × 9", 3, 1, 9, 1, 598.0 / 4096, false},
+		{"todos em um período", 10, 1, 0, 1, 2.0 / 1024, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PoissonRates(tt.k1, tt.t1, tt.k2, tt.t2)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("p = %v, esperado %v", got, tt.want)
+			}
+			if s := (MetricDelta{PValue: got}).Significant(); s != tt.signif {
+				t.Errorf("significativo = %v, esperado %v", s, tt.signif)
+			}
+		})
+	}
+}
+
+func TestVerdict(t *testing.T) {
+	tests := []struct {
+		delta, p float64
+		want     string
+	}{
+		{-5, -1, "dados insuficientes"},
+		{-5, 0.2, "sem diferença significativa"},
+		{-5, 0.01, "melhorou"},
+		{5, 0.01, "piorou"},
+		{5, SignificanceLevel, "sem diferença significativa"},
+	}
+	for _, tt := range tests {
+		if got := (MetricDelta{Delta: tt.delta, PValue: tt.p}).Verdict(); got != tt.want {
+			t.Errorf("Δ %v, p %v: %q, esperado %q", tt.delta, tt.p, got, tt.want)
+		}
+	}
+}
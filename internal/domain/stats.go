@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+// RangeStats resume as amostras de um host em um período, calculado no banco
+type RangeStats struct {
+	HostID     string     `json:"hostId"`
+	Resolution Resolution `json:"resolution"` // Nível de onde os dados vieram
+	Start      time.Time  `json:"start"`
+	End        time.Time  `json:"end"`
+	Count      int        `json:"count"`
+	LossCount  int        `json:"lossCount"`
+	LossPct    float64    `json:"lossPct"`
+	LatMin     int64      `json:"latMin"` // Latências em microsegundos, só pacotes respondidos
+	LatAvg     int64      `json:"latAvg"`
+	LatMax     int64      `json:"latMax"`
+	LatP50     int64      `json:"latP50"`
+	LatP95     int64      `json:"latP95"`
+	LatP99     int64      `json:"latP99"`
+	JitterAvg  int64      `json:"jitterAvg"`
+}
+
+// StatsFromRollup converte um intervalo agregado no resumo do período
+func StatsFromRollup(r Rollup, end time.Time) RangeStats {
+	st := RangeStats{
+		HostID: r.HostID, Resolution: r.Resolution, Start: r.Bucket, End: end,
+		Count: r.Count, LossCount: r.LossCount,
+		LatMin: r.LatMin, LatAvg: r.LatAvg, LatMax: r.LatMax,
+		LatP50: r.LatP50, LatP95: r.LatP95, LatP99: r.LatP99,
+		JitterAvg: r.JitterAvg,
+	}
+	if r.Count > 0 {
+		st.LossPct = float64(r.LossCount) / float64(r.Count) * 100
+	}
+	return st
+}
+
+// HistogramBin é uma faixa do histograma de latência
+type HistogramBin struct {
+	From  int64 `json:"from"` // Limite inferior em microsegundos (inclusivo)
+	To    int64 `json:"to"`   // Limite superior em microsegundos (exclusivo)
+	Count int   `json:"count"`
+}
+
+// SeriesPoint é um intervalo da série histórica usada pelos gráficos
+type SeriesPoint struct {
+	Time      time.Time `json:"time"` // Início do intervalo
+	Count     int       `json:"count"`
+	LatMin    int64     `json:"latMin"` // Latências em microsegundos, só pacotes respondidos
+	LatAvg    int64     `json:"latAvg"`
+	LatMax    int64     `json:"latMax"`
+	JitterAvg int64     `json:"jitterAvg"`
+	LossRatio float64   `json:"lossRatio"` // 0 a 1
+}
+
+// HostSeries é a série histórica de um host em intervalos de tamanho fixo
+type HostSeries struct {
+	HostID     string        `json:"hostId"`
+	Resolution Resolution    `json:"resolution"` // Nível de onde os dados vieram
+	BucketSec  int64         `json:"bucketSec"`
+	Points     []SeriesPoint `json:"points"`
+}
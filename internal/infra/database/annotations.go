@@ -0,0 +1,73 @@
+package database
+
+import (
+	"database/sql"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+func (r *SQLiteBatcher) AddAnnotation(a domain.Annotation) (domain.Annotation, error) {
+	var end sql.NullInt64
+	if a.End != nil {
+		end = sql.NullInt64{Int64: a.End.UnixMicro(), Valid: true}
+	}
+
+	err := r.onWriter(func() error {
+		res, err := r.db.Exec(`
+			INSERT INTO annotations (host_id, start_ts, end_ts, text, kind, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			a.HostID, a.Start.UnixMicro(), end, a.Text, a.Kind, a.CreatedAt.UnixMicro())
+		if err != nil {
+			return err
+		}
+		a.ID, err = res.LastInsertId()
+		return err
+	})
+	return a, err
+}
+
+func (r *SQLiteBatcher) ListAnnotations(hostID string, start, end time.Time) ([]domain.Annotation, error) {
+	rows, err := r.reader.Query(`
+		SELECT id, host_id, start_ts, end_ts, text, kind, created_at
+		FROM annotations
+		WHERE start_ts <= ? AND COALESCE(end_ts, start_ts) >= ?
+			AND (? = '' OR host_id = '' OR host_id = ?)
+		ORDER BY start_ts, id`,
+		end.UnixMicro(), start.UnixMicro(), hostID, hostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.Annotation{}
+	for rows.Next() {
+		var (
+			a                  domain.Annotation
+			startTS, createdAt int64
+			endTS              sql.NullInt64
+		)
+		if err := rows.Scan(&a.ID, &a.HostID, &startTS, &endTS, &a.Text, &a.Kind, &createdAt); err != nil {
+			return nil, err
+		}
+		a.Start, a.CreatedAt = time.UnixMicro(startTS), time.UnixMicro(createdAt)
+		if endTS.Valid {
+			t := time.UnixMicro(endTS.Int64)
+			a.End = &t
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteBatcher) DeleteAnnotation(id int64) error {
+	return r.onWriter(func() error {
+		res, err := r.db.Exec("DELETE FROM annotations WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return domain.ErrAnnotationNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,257 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"lag-monitor/internal/domain"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Snapshots ficam em <dir do banco>/backups com o horário no nome:
+// lagmonitor-20261019-153000.db
+const (
+	snapshotDir    = "backups"
+	snapshotLayout = "20060102-150405"
+	restoreSuffix  = ".restore" // Cópia aguardando a próxima inicialização
+)
+
+// backupDB copia o banco de src para dest pela API de backup do SQLite. A cópia é
+// feita em um único passo, dentro de uma transação de leitura: o resultado é
+// consistente mesmo com o escritor ativo (WAL).
+func backupDB(src *sql.DB, dest string) error {
+	ctx := context.Background()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	destDB, err := sql.Open("sqlite3", dest)
+	if err != nil {
+		return err
+	}
+	defer destDB.Close()
+
+	destConn, err := destDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	return destConn.Raw(func(dc interface{}) error {
+		return srcConn.Raw(func(sc interface{}) error {
+			b, err := dc.(*sqlite3.SQLiteConn).Backup("main", sc.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			if _, err := b.Step(-1); err != nil {
+				b.Close()
+				return err
+			}
+			return b.Finish()
+		})
+	})
+}
+
+// checkIntegrity valida o arquivo antes de tratá-lo como um snapshot utilizável
+func checkIntegrity(path string) error {
+	db, err := sql.Open("sqlite3", fmt.Sprintf(readerDSN, path))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("arquivo corrompido: %s", result)
+	}
+	return nil
+}
+
+// BackupFile cria um snapshot do banco em dbPath sem abrir o repositório (usado
+// pela linha de comando, inclusive com o app em execução)
+func BackupFile(dbPath string) (domain.Snapshot, error) {
+	src, err := sql.Open("sqlite3", fmt.Sprintf(readerDSN, dbPath))
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+	defer src.Close()
+	return createSnapshot(src, dbPath)
+}
+
+// CreateSnapshot grava uma cópia consistente do banco na pasta de backups
+func (r *SQLiteBatcher) CreateSnapshot() (domain.Snapshot, error) {
+	return createSnapshot(r.reader, r.path)
+}
+
+func createSnapshot(src *sql.DB, dbPath string) (domain.Snapshot, error) {
+	dir := filepath.Join(filepath.Dir(dbPath), snapshotDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return domain.Snapshot{}, err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.db", snapshotPrefix(dbPath), now.Format(snapshotLayout))
+	dest := filepath.Join(dir, name)
+	if _, err := os.Stat(dest); err == nil {
+		return domain.Snapshot{}, fmt.Errorf("%s já existe", name)
+	}
+
+	// Grava em um arquivo temporário: um backup interrompido nunca aparece na lista
+	tmp := dest + ".tmp"
+	os.Remove(tmp)
+	if err := backupDB(src, tmp); err != nil {
+		os.Remove(tmp)
+		return domain.Snapshot{}, err
+	}
+	if err := checkIntegrity(tmp); err != nil {
+		os.Remove(tmp)
+		return domain.Snapshot{}, err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return domain.Snapshot{}, err
+	}
+
+	info, err := os.Stat(dest)
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+	return domain.Snapshot{Name: name, Path: dest, Size: info.Size(), CreatedAt: now}, nil
+}
+
+func snapshotPrefix(dbPath string) string {
+	return strings.TrimSuffix(filepath.Base(dbPath), filepath.Ext(dbPath))
+}
+
+// ListSnapshots retorna os snapshots do banco, do mais recente para o mais antigo
+func (r *SQLiteBatcher) ListSnapshots() ([]domain.Snapshot, error) {
+	return ListSnapshots(r.path)
+}
+
+func ListSnapshots(dbPath string) ([]domain.Snapshot, error) {
+	dir := filepath.Join(filepath.Dir(dbPath), snapshotDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []domain.Snapshot{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	prefix := snapshotPrefix(dbPath) + "-"
+	out := []domain.Snapshot{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		created, err := time.ParseInLocation(snapshotLayout, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".db"), time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, domain.Snapshot{Name: name, Path: filepath.Join(dir, name), Size: info.Size(), CreatedAt: created})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// RotateSnapshots mantém apenas os keep snapshots mais recentes
+func (r *SQLiteBatcher) RotateSnapshots(keep int) (int, error) {
+	snaps, err := ListSnapshots(r.path)
+	if err != nil || keep <= 0 || len(snaps) <= keep {
+		return 0, err
+	}
+
+	removed := 0
+	for _, s := range snaps[keep:] {
+		if err := os.Remove(s.Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RestoreSnapshot agenda a restauração para a próxima inicialização
+func (r *SQLiteBatcher) RestoreSnapshot(name string) error {
+	return StageRestore(r.path, name)
+}
+
+// StageRestore valida o snapshot e deixa uma cópia dele ao lado do banco. O banco em
+// uso só é trocado por ApplyPendingRestore, antes de ser aberto.
+func StageRestore(dbPath, name string) error {
+	if name != filepath.Base(name) {
+		return fmt.Errorf("nome de snapshot inválido: %q", name)
+	}
+	src := filepath.Join(filepath.Dir(dbPath), snapshotDir, name)
+	if err := checkIntegrity(src); err != nil {
+		return fmt.Errorf("snapshot %s: %w", name, err)
+	}
+
+	staged := dbPath + restoreSuffix
+	if err := copyFile(src, staged+".tmp"); err != nil {
+		os.Remove(staged + ".tmp")
+		return err
+	}
+	return os.Rename(staged+".tmp", staged)
+}
+
+// ApplyPendingRestore troca o banco pela cópia agendada, se houver. O banco atual é
+// preservado (com seu WAL) como <banco>.before-restore-<horário>.bak.
+func ApplyPendingRestore(dbPath string) (bool, error) {
+	staged := dbPath + restoreSuffix
+	if _, err := os.Stat(staged); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		bak := fmt.Sprintf("%s.before-restore-%s.bak", dbPath, time.Now().Format(snapshotLayout))
+		if err := os.Rename(dbPath, bak); err != nil {
+			return false, err
+		}
+		if _, err := os.Stat(dbPath + "-wal"); err == nil {
+			if err := os.Rename(dbPath+"-wal", bak+"-wal"); err != nil {
+				return false, err
+			}
+		}
+		os.Remove(dbPath + "-shm")
+	}
+	return true, os.Rename(staged, dbPath)
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// Import grava amostras de outra origem, em qualquer ordem, ignorando as que já
+// existem. Os agregados dos intervalos afetados são recalculados na mesma transação.
+func (r *SQLiteBatcher) Import(results []domain.PingResult, precision time.Duration) (imported int, err error) {
+	if len(results) == 0 {
+		return 0, nil
+	}
+	err = r.onWriter(func() error {
+		imported, err = r.importResults(results, precision)
+		return err
+	})
+	return imported, err
+}
+
+func (r *SQLiteBatcher) importResults(results []domain.PingResult, precision time.Duration) (int, error) {
+	window := max(precision.Microseconds(), 1)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	stmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO pings(host_id, latency, jitter, loss, ts)
+		SELECT ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM pings WHERE host_id = ? AND ts >= ? AND ts < ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	dirty := newRollupTracker()
+	imported := 0
+	for _, d := range results {
+		ts := d.Timestamp.UnixMicro()
+		res, err := stmt.Exec(d.HostID, d.Latency, d.Jitter, d.Loss, ts, d.HostID, ts, ts+window)
+		if err != nil {
+			return 0, fmt.Errorf("insert: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			imported++
+			dirty.mark([]domain.PingResult{d})
+		}
+	}
+
+	for res, set := range dirty.dirty {
+		for key := range set {
+			if err := recomputeBucket(tx, res, key); err != nil {
+				return 0, fmt.Errorf("agregados: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return imported, nil
+}
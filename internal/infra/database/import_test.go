@@ -0,0 +1,94 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lag-monitor/internal/domain"
+)
+
+func TestImportDedup(t *testing.T) {
+	base := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	sample := func(host string, offset time.Duration) domain.PingResult {
+		return domain.PingResult{HostID: host, Latency: 10_000, Timestamp: base.Add(offset)}
+	}
+
+	tests := []struct {
+		name      string
+		batch     []domain.PingResult
+		precision time.Duration
+		want      int
+	}{
+		{"mesmo instante", []domain.PingResult{sample("a", 0)}, time.Microsecond, 0},
+		{"instante seguinte", []domain.PingResult{sample("a", time.Microsecond)}, time.Microsecond, 1},
+		{"precisão zero vale 1µs", []domain.PingResult{sample("a", 0)}, 0, 0},
+		{"outro host", []domain.PingResult{sample("b", 0)}, time.Microsecond, 1},
+		// A origem em segundos cobre [ts, ts+1s): a amostra existente cai dentro
+		{"dentro da precisão", []domain.PingResult{sample("a", -500*time.Millisecond)}, time.Second, 0},
+		{"antes da precisão", []domain.PingResult{sample("a", -time.Second)}, time.Second, 1},
+		{"depois da existente", []domain.PingResult{sample("a", 500*time.Millisecond)}, time.Second, 1},
+		{"repetida no mesmo lote", []domain.PingResult{sample("a", time.Minute), sample("a", time.Minute)}, time.Microsecond, 1},
+		{"fora de ordem", []domain.PingResult{sample("a", 2*time.Second), sample("a", -time.Hour), sample("a", 0), sample("a", time.Second)}, time.Second, 3},
+		{"lote vazio", nil, time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := NewSQLiteRepo(filepath.Join(t.TempDir(), "lagmonitor.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			// Já existe uma amostra de "a" em base
+			if n, err := repo.Import([]domain.PingResult{sample("a", 0)}, time.Microsecond); err != nil || n != 1 {
+				t.Fatalf("amostra inicial: %d, %v", n, err)
+			}
+
+			n, err := repo.Import(tt.batch, tt.precision)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.want {
+				t.Errorf("importadas = %d, esperado %d", n, tt.want)
+			}
+			// Reimportar o mesmo lote não grava nada
+			if n, err := repo.Import(tt.batch, tt.precision); err != nil || n != 0 {
+				t.Errorf("reimportação: %d, %v", n, err)
+			}
+		})
+	}
+}
+
+func TestImportUpdatesRollups(t *testing.T) {
+	repo, err := NewSQLiteRepo(filepath.Join(t.TempDir(), "lagmonitor.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	minute := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	var batch []domain.PingResult
+	for i := 0; i < 60; i++ {
+		batch = append(batch, domain.PingResult{
+			HostID: "a", Latency: 10_000, Loss: i < 6, Timestamp: minute.Add(time.Duration(i) * time.Second),
+		})
+	}
+	if _, err := repo.Import(batch[:30], time.Second); err != nil {
+		t.Fatal(err)
+	}
+	// O segundo lote repete parte do primeiro
+	if n, err := repo.Import(batch[20:], time.Second); err != nil || n != 30 {
+		t.Fatalf("segundo lote: %d, %v", n, err)
+	}
+
+	for _, res := range []domain.Resolution{domain.ResolutionMinute, domain.ResolutionHour} {
+		rollups, err := repo.GetRollups("a", res, minute, minute.Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rollups) != 1 || rollups[0].Count != 60 || rollups[0].LossCount != 6 {
+			t.Errorf("agregados %s = %+v; esperado um intervalo com 60 amostras e 6 perdas", res, rollups)
+		}
+	}
+}
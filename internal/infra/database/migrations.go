@@ -0,0 +1,306 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// migration é um passo de evolução do esquema. Cada passo roda em uma transação
+// própria e, ao terminar, grava sua versão em schema_version. Passos que não podem rodar
+// em transação (VACUUM) usam upDB e precisam ser idempotentes.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+	upDB    func(db *sql.DB) error
+}
+
+// migrations deve ser mantida em ordem crescente de versão. Nunca altere um passo já
+// publicado: crie um novo.
+var migrations = []migration{
+	{version: 1, name: "tabelas iniciais", up: execSQL(`
+		CREATE TABLE IF NOT EXISTS pings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			host_id TEXT,
+			latency INTEGER,
+			jitter INTEGER,
+			loss BOOLEAN,
+			timestamp DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT
+		);`)},
+	{version: 2, name: "timestamps em epoch (µs) e índices", up: migratePingsToEpoch},
+	{version: 3, name: "agregados por minuto e por hora", up: createRollupTables},
+	{version: 4, name: "uma amostra por host e instante", up: execSQL(`
+		DELETE FROM pings WHERE id NOT IN (SELECT MIN(id) FROM pings GROUP BY host_id, ts);
+		DROP INDEX idx_pings_host_ts;
+		CREATE UNIQUE INDEX idx_pings_host_ts ON pings (host_id, ts);`)},
+	{version: 5, name: "anotações", up: execSQL(`
+		CREATE TABLE annotations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			host_id TEXT NOT NULL DEFAULT '', -- vazio vale para todos os hosts
+			start_ts INTEGER NOT NULL,        -- epoch em microsegundos
+			end_ts INTEGER,                   -- NULL para um instante
+			text TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+		CREATE INDEX idx_annotations_start ON annotations (start_ts);`)},
+	{version: 6, name: "preferências passam para o settings.json", up: execSQL(`DROP TABLE IF EXISTS settings;`)},
+	{version: 7, name: "auto_vacuum incremental", upDB: enableIncrementalVacuum},
+}
+
+// execSQL cria um passo a partir de SQL puro
+func execSQL(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+// migrate aplica as migrações pendentes. Antes de alterar um banco existente, grava
+// uma cópia consistente ao lado do arquivo original.
+func migrate(db *sql.DB, dbPath string) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT,
+			applied_at DATETIME
+		);`); err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	latest := migrations[len(migrations)-1].version
+	if current > latest {
+		return fmt.Errorf("banco na versão %d, mais nova que a suportada (%d)", current, latest)
+	}
+	if current == latest {
+		return nil
+	}
+
+	if err := backupBeforeMigration(db, dbPath, current); err != nil {
+		return fmt.Errorf("backup pré-migração: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migração %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func schemaVersion(db *sql.DB) (int, error) {
+	var v sql.NullInt64
+	if err := db.QueryRow("SELECT MAX(version) FROM schema_version").Scan(&v); err != nil {
+		return 0, err
+	}
+	return int(v.Int64), nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	if m.upDB != nil {
+		if err := m.upDB(db); err != nil {
+			return err
+		}
+		_, err := db.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+			m.version, m.name, time.Now())
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+		m.version, m.name, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// backupBeforeMigration copia o banco (via VACUUM INTO, consistente mesmo com WAL)
+// quando ele já contém dados. Bancos novos não precisam de cópia.
+func backupBeforeMigration(db *sql.DB, dbPath string, current int) error {
+	var tables int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'pings'").Scan(&tables); err != nil {
+		return err
+	}
+	if tables == 0 {
+		return nil
+	}
+
+	dest := fmt.Sprintf("%s.pre-v%d-%s.bak", dbPath, current, time.Now().Format("20060102-150405"))
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%s já existe", dest)
+	}
+	_, err := db.Exec("VACUUM INTO ?", dest)
+	return err
+}
+
+// migratePingsToEpoch recria a tabela pings com o timestamp como inteiro (epoch em
+// microsegundos) e cria os índices usados por GetHistory e pela retenção. A conversão
+// é feita em Go porque o formato textual do DATETIME legado inclui o fuso horário.
+func migratePingsToEpoch(tx *sql.Tx) error {
+	if _, err := tx.Exec(`
+		CREATE TABLE pings_v2 (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			host_id TEXT NOT NULL,
+			latency INTEGER NOT NULL DEFAULT 0,
+			jitter INTEGER NOT NULL DEFAULT 0,
+			loss INTEGER NOT NULL DEFAULT 0,
+			ts INTEGER NOT NULL -- epoch em microsegundos
+		);`); err != nil {
+		return err
+	}
+
+	const chunk = 5000
+	var lastID int64
+	for {
+		rows, err := tx.Query(`
+			SELECT id, host_id, latency, jitter, loss, timestamp
+			FROM pings WHERE id > ? ORDER BY id LIMIT ?`, lastID, chunk)
+		if err != nil {
+			return err
+		}
+
+		type legacyRow struct {
+			hostID          string
+			latency, jitter int64
+			loss            bool
+			ts              int64
+		}
+		var batch []legacyRow
+		scanned := 0
+		for rows.Next() {
+			scanned++
+			var (
+				row     legacyRow
+				hostID  sql.NullString
+				lat     sql.NullInt64
+				jit     sql.NullInt64
+				loss    sql.NullBool
+				rawTime interface{}
+			)
+			if err := rows.Scan(&lastID, &hostID, &lat, &jit, &loss, &rawTime); err != nil {
+				rows.Close()
+				return err
+			}
+			ts, ok := legacyTime(rawTime)
+			if !ok || !hostID.Valid {
+				continue // Linha corrompida: não há como posicioná-la no tempo
+			}
+			row.hostID, row.latency, row.jitter, row.loss, row.ts = hostID.String, lat.Int64, jit.Int64, loss.Bool, ts.UnixMicro()
+			batch = append(batch, row)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return err
+		}
+		rows.Close()
+
+		if scanned == 0 {
+			break
+		}
+		for _, row := range batch {
+			if _, err := tx.Exec("INSERT INTO pings_v2 (host_id, latency, jitter, loss, ts) VALUES (?, ?, ?, ?, ?)",
+				row.hostID, row.latency, row.jitter, row.loss, row.ts); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := tx.Exec(`
+		DROP TABLE pings;
+		ALTER TABLE pings_v2 RENAME TO pings;
+		CREATE INDEX idx_pings_host_ts ON pings (host_id, ts);
+		CREATE INDEX idx_pings_ts ON pings (ts);`)
+	return err
+}
+
+// legacyTime interpreta o valor gravado na antiga coluna DATETIME
+func legacyTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero() // O driver devolve o instante zero quando o texto não é uma data
+	case int64:
+		return time.Unix(t, 0), true
+	case []byte:
+		return parseLegacyTime(string(t))
+	case string:
+		return parseLegacyTime(t)
+	}
+	return time.Time{}, false
+}
+
+func parseLegacyTime(s string) (time.Time, bool) {
+	for _, layout := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// createRollupTables cria os níveis de agregação e os preenche com o histórico existente
+func createRollupTables(tx *sql.Tx) error {
+	for _, table := range []string{"pings_1m", "pings_1h"} {
+		if _, err := tx.Exec(fmt.Sprintf(`
+			CREATE TABLE %s (
+				host_id TEXT NOT NULL,
+				bucket INTEGER NOT NULL, -- início do intervalo, epoch em microsegundos
+				count INTEGER NOT NULL,
+				loss_count INTEGER NOT NULL,
+				lat_min INTEGER NOT NULL,
+				lat_avg INTEGER NOT NULL,
+				lat_max INTEGER NOT NULL,
+				lat_p50 INTEGER NOT NULL,
+				lat_p95 INTEGER NOT NULL,
+				lat_p99 INTEGER NOT NULL,
+				jitter_avg INTEGER NOT NULL,
+				jitter_max INTEGER NOT NULL,
+				PRIMARY KEY (host_id, bucket)
+			) WITHOUT ROWID;
+			CREATE INDEX idx_%[1]s_bucket ON %[1]s (bucket);`, table)); err != nil {
+			return err
+		}
+	}
+	return backfillRollups(tx)
+}
+
+// enableIncrementalVacuum converte bancos criados sem auto_vacuum. A mudança exige um
+// VACUUM completo, feito aqui uma única vez, antes de o escritor começar. Bancos novos
+// já nascem no modo incremental (writerDSN).
+func enableIncrementalVacuum(db *sql.DB) error {
+	var mode int
+	if err := db.QueryRow("PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		return err
+	}
+	if mode == 2 { // INCREMENTAL
+		return nil
+	}
+	if _, err := db.Exec("PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+		return err
+	}
+	_, err := db.Exec("VACUUM")
+	return err
+}
@@ -0,0 +1,247 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "lagmonitor.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+// autoVacuum lê o modo de auto_vacuum gravado no cabeçalho do banco
+func autoVacuum(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var mode int
+	if err := db.QueryRow("PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		t.Fatal(err)
+	}
+	return mode
+}
+
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+	files, err := filepath.Glob(path + ".pre-v*.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	db, path := openTestDB(t)
+	if err := migrate(db, path); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := schemaVersion(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := migrations[len(migrations)-1].version; v != latest {
+		t.Errorf("versão = %d, esperado %d", v, latest)
+	}
+	for table, want := range map[string]bool{
+		"pings": true, "pings_1m": true, "pings_1h": true, "annotations": true, "settings": false,
+	} {
+		if got := tableExists(t, db, table); got != want {
+			t.Errorf("tabela %s existe = %v, esperado %v", table, got, want)
+		}
+	}
+	if files := backups(t, path); len(files) != 0 {
+		t.Errorf("banco novo não deveria ter backup: %v", files)
+	}
+	if mode := autoVacuum(t, db); mode != 2 {
+		t.Errorf("auto_vacuum = %d, esperado 2 (incremental)", mode)
+	}
+
+	// Rodar de novo não faz nada
+	if err := migrate(db, path); err != nil {
+		t.Fatal(err)
+	}
+	if files := backups(t, path); len(files) != 0 {
+		t.Errorf("nenhuma migração pendente, mas houve backup: %v", files)
+	}
+}
+
+func TestMigrateBackup(t *testing.T) {
+	db, path := openTestDB(t)
+	// Banco anterior às migrações, sem schema_version
+	if _, err := db.Exec(`
+		CREATE TABLE pings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			host_id TEXT,
+			latency INTEGER,
+			jitter INTEGER,
+			loss BOOLEAN,
+			timestamp DATETIME
+		);`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrate(db, path); err != nil {
+		t.Fatal(err)
+	}
+	files := backups(t, path)
+	if len(files) != 1 {
+		t.Fatalf("esperado um backup pré-migração, encontrados %v", files)
+	}
+	bak, err := sql.Open("sqlite3", files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bak.Close()
+	if v, err := schemaVersion(bak); err != nil || v != 0 || !tableExists(t, bak, "pings") {
+		t.Errorf("o backup deveria ter o esquema anterior à migração (versão %d, %v)", v, err)
+	}
+
+	if err := migrate(db, path); err != nil {
+		t.Fatal(err)
+	}
+	if files := backups(t, path); len(files) != 1 {
+		t.Errorf("nenhuma migração pendente, mas houve backup: %v", files)
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	db, path := openTestDB(t)
+
+	// Esquema anterior às migrações: timestamp como DATETIME textual
+	if _, err := db.Exec(`
+		CREATE TABLE pings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			host_id TEXT,
+			latency INTEGER,
+			jitter INTEGER,
+			loss BOOLEAN,
+			timestamp DATETIME
+		);
+		CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT);
+		INSERT INTO settings VALUES ('retention_days', '30');`); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	rows := []struct {
+		hostID  interface{}
+		latency int64
+		loss    bool
+		time    interface{}
+	}{
+		{"a", 10_000, false, ts},
+		{"a", 11_000, false, ts}, // Mesmo host e instante: fica só a primeira
+		{"a", 0, true, ts.Add(time.Second)},
+		{"b", 20_000, false, "2024-03-04 07:00:02-03:00"},
+		{"b", 21_000, false, "2024-03-04T10:00:03Z"},
+		{"b", 22_000, false, "ontem"}, // Sem como posicionar no tempo
+		{nil, 23_000, false, ts},      // Sem host
+	}
+	for _, r := range rows {
+		if _, err := db.Exec("INSERT INTO pings (host_id, latency, jitter, loss, timestamp) VALUES (?, ?, 0, ?, ?)",
+			r.hostID, r.latency, r.loss, r.time); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := migrate(db, path); err != nil {
+		t.Fatal(err)
+	}
+
+	if files := backups(t, path); len(files) != 1 {
+		t.Errorf("esperado um backup pré-migração, encontrados %v", files)
+	}
+	if tableExists(t, db, "settings") {
+		t.Error("tabela settings deveria ter sido removida")
+	}
+	// A conversão para auto_vacuum incremental (VACUUM completo) acontece na migração
+	if mode := autoVacuum(t, db); mode != 2 {
+		t.Errorf("auto_vacuum = %d, esperado 2 (incremental)", mode)
+	}
+
+	tests := []struct {
+		hostID  string
+		ts      time.Time
+		latency int64
+		loss    bool
+	}{
+		{"a", ts, 10_000, false},
+		{"a", ts.Add(time.Second), 0, true},
+		{"b", ts.Add(2 * time.Second), 20_000, false},
+		{"b", ts.Add(3 * time.Second), 21_000, false},
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM pings").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != len(tests) {
+		t.Errorf("amostras migradas = %d, esperado %d", count, len(tests))
+	}
+	for _, tt := range tests {
+		var latency int64
+		var loss bool
+		err := db.QueryRow("SELECT latency, loss FROM pings WHERE host_id = ? AND ts = ?",
+			tt.hostID, tt.ts.UnixMicro()).Scan(&latency, &loss)
+		if err != nil {
+			t.Errorf("%s em %s: %v", tt.hostID, tt.ts.Format(time.RFC3339), err)
+			continue
+		}
+		if latency != tt.latency || loss != tt.loss {
+			t.Errorf("%s em %s: latência %d perda %v, esperado %d %v",
+				tt.hostID, tt.ts.Format(time.RFC3339), latency, loss, tt.latency, tt.loss)
+		}
+	}
+
+	// O histórico existente já entra nos agregados
+	var minutes int
+	if err := db.QueryRow("SELECT COUNT(*) FROM pings_1m").Scan(&minutes); err != nil {
+		t.Fatal(err)
+	}
+	if minutes != 2 {
+		t.Errorf("agregados por minuto = %d, esperado 2 (um por host)", minutes)
+	}
+}
+
+func TestParseLegacyTime(t *testing.T) {
+	want := time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in string
+		ok bool
+	}{
+		{"2024-03-04 10:00:00", true},
+		{"2024-03-04 10:00:00+00:00", true},
+		{"2024-03-04 07:00:00-03:00", true},
+		{"2024-03-04T10:00:00+00:00", true},
+		{"2024-03-04 10:00:00.000000000+00:00", true},
+		{"", false},
+		{"04/03/2024 10:00", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseLegacyTime(tt.in)
+		if ok != tt.ok {
+			t.Errorf("parseLegacyTime(%q) ok = %v, esperado %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if ok && !got.Equal(want) {
+			t.Errorf("parseLegacyTime(%q) = %s, esperado %s", tt.in, got, want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// Tabela de cada nível de agregação
+var rollupTables = map[domain.Resolution]string{
+	domain.ResolutionMinute: "pings_1m",
+	domain.ResolutionHour:   "pings_1h",
+}
+
+// Janela reavaliada na inicialização para recuperar agregados perdidos num encerramento abrupto
+const rollupCatchUp = 6 * time.Hour
+
+// execer é satisfeito por *sql.DB e *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type bucketKey struct {
+	hostID string
+	start  int64 // epoch em microsegundos
+}
+
+// rollupTracker guarda os intervalos que receberam amostras desde o último recálculo.
+// Só é acessado pelo goroutine escritor.
+type rollupTracker struct {
+	dirty map[domain.Resolution]map[bucketKey]bool
+}
+
+func newRollupTracker() *rollupTracker {
+	return &rollupTracker{dirty: map[domain.Resolution]map[bucketKey]bool{
+		domain.ResolutionMinute: {},
+		domain.ResolutionHour:   {},
+	}}
+}
+
+func bucketStart(ts time.Time, res domain.Resolution) int64 {
+	return ts.Truncate(res.Duration()).UnixMicro()
+}
+
+func (t *rollupTracker) mark(data []domain.PingResult) {
+	for _, d := range data {
+		for res, set := range t.dirty {
+			set[bucketKey{d.HostID, bucketStart(d.Timestamp, res)}] = true
+		}
+	}
+}
+
+// refreshRollups recalcula, a partir das amostras brutas, os intervalos marcados.
+// Minutos são recalculados a cada lote (são baratos); horas só quando fecham ou
+// quando final é true (encerramento).
+func (r *SQLiteBatcher) refreshRollups(final bool) {
+	now := time.Now()
+
+	for res, set := range r.rollups.dirty {
+		for key := range set {
+			end := time.UnixMicro(key.start).Add(res.Duration())
+			closed := !end.After(now)
+			if res == domain.ResolutionHour && !closed && !final {
+				continue
+			}
+
+			if err := recomputeBucket(r.db, res, key); err != nil {
+				continue // Fica marcado e será tentado de novo no próximo lote
+			}
+			// Intervalos ainda abertos serão marcados de novo pelas próximas amostras
+			delete(set, key)
+		}
+	}
+}
+
+// recomputeBucket lê as amostras brutas do intervalo e grava o agregado
+func recomputeBucket(db execer, res domain.Resolution, key bucketKey) error {
+	start := time.UnixMicro(key.start)
+	rows, err := db.Query(`
+		SELECT latency, jitter, loss FROM pings
+		WHERE host_id = ? AND ts >= ? AND ts < ?`,
+		key.hostID, key.start, start.Add(res.Duration()).UnixMicro())
+	if err != nil {
+		return err
+	}
+
+	var samples []domain.PingResult
+	for rows.Next() {
+		var d domain.PingResult
+		if err := rows.Scan(&d.Latency, &d.Jitter, &d.Loss); err != nil {
+			rows.Close()
+			return err
+		}
+		samples = append(samples, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return nil // Amostras já removidas pela retenção
+	}
+
+	return upsertRollup(db, domain.BuildRollup(key.hostID, res, start, samples))
+}
+
+func upsertRollup(db execer, ru domain.Rollup) error {
+	_, err := db.Exec(fmt.Sprintf(`
+		INSERT OR REPLACE INTO %s (host_id, bucket, count, loss_count,
+			lat_min, lat_avg, lat_max, lat_p50, lat_p95, lat_p99, jitter_avg, jitter_max)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, rollupTables[ru.Resolution]),
+		ru.HostID, ru.Bucket.UnixMicro(), ru.Count, ru.LossCount,
+		ru.LatMin, ru.LatAvg, ru.LatMax, ru.LatP50, ru.LatP95, ru.LatP99, ru.JitterAvg, ru.JitterMax)
+	return err
+}
+
+// catchUpRollups marca os intervalos recentes que têm amostras brutas mas nenhum agregado
+func (r *SQLiteBatcher) catchUpRollups() error {
+	since := time.Now().Add(-rollupCatchUp).UnixMicro()
+
+	for res, table := range rollupTables {
+		size := res.Duration().Microseconds()
+		rows, err := r.db.Query(fmt.Sprintf(`
+			SELECT DISTINCT host_id, (ts / %[1]d) * %[1]d AS bucket FROM pings p
+			WHERE ts >= ? AND NOT EXISTS (
+				SELECT 1 FROM %[2]s r WHERE r.host_id = p.host_id AND r.bucket = (p.ts / %[1]d) * %[1]d
+			)`, size, table), since)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var key bucketKey
+			if err := rows.Scan(&key.hostID, &key.start); err != nil {
+				rows.Close()
+				return err
+			}
+			r.rollups.dirty[res][key] = true
+		}
+		rows.Close()
+	}
+	return nil
+}
+
+// GetRollups retorna os agregados do host no período, em ordem cronológica
+func (r *SQLiteBatcher) GetRollups(hostID string, res domain.Resolution, start, end time.Time) ([]domain.Rollup, error) {
+	table, ok := rollupTables[res]
+	if !ok {
+		return nil, fmt.Errorf("resolução %q não possui agregados", res)
+	}
+
+	rows, err := r.reader.Query(fmt.Sprintf(`
+		SELECT bucket, count, loss_count, lat_min, lat_avg, lat_max,
+			lat_p50, lat_p95, lat_p99, jitter_avg, jitter_max
+		FROM %s
+		WHERE host_id = ? AND bucket BETWEEN ? AND ?
+		ORDER BY bucket ASC`, table), hostID, start.Truncate(res.Duration()).UnixMicro(), end.UnixMicro())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.Rollup
+	for rows.Next() {
+		ru := domain.Rollup{HostID: hostID, Resolution: res}
+		var bucket int64
+		if err := rows.Scan(&bucket, &ru.Count, &ru.LossCount, &ru.LatMin, &ru.LatAvg, &ru.LatMax,
+			&ru.LatP50, &ru.LatP95, &ru.LatP99, &ru.JitterAvg, &ru.JitterMax); err != nil {
+			return nil, err
+		}
+		ru.Bucket = time.UnixMicro(bucket)
+		out = append(out, ru)
+	}
+	return out, rows.Err()
+}
+
+// CleanOldRollups remove agregados mais antigos que o número de dias especificado
+func (r *SQLiteBatcher) CleanOldRollups(res domain.Resolution, days int) (int64, error) {
+	table, ok := rollupTables[res]
+	if !ok {
+		return 0, fmt.Errorf("resolução %q não possui agregados", res)
+	}
+
+	var removed int64
+	err := r.onWriter(func() error {
+		result, err := r.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE bucket < ?", table), cutoff(days))
+		if err != nil {
+			return err
+		}
+		removed, err = result.RowsAffected()
+		return err
+	})
+	return removed, err
+}
+
+// backfillRollups gera os agregados de todo o histórico bruto existente. Usado pela
+// migração que cria as tabelas; percorre as amostras em ordem, um intervalo por vez.
+func backfillRollups(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT host_id, latency, jitter, loss, ts FROM pings ORDER BY host_id, ts")
+	if err != nil {
+		return err
+	}
+
+	type pending struct {
+		key     bucketKey
+		samples []domain.PingResult
+	}
+	open := map[domain.Resolution]*pending{}
+	var done []domain.Rollup
+
+	closeBucket := func(res domain.Resolution) {
+		if p := open[res]; p != nil && len(p.samples) > 0 {
+			done = append(done, domain.BuildRollup(p.key.hostID, res, time.UnixMicro(p.key.start), p.samples))
+		}
+		delete(open, res)
+	}
+
+	for rows.Next() {
+		var d domain.PingResult
+		var ts int64
+		if err := rows.Scan(&d.HostID, &d.Latency, &d.Jitter, &d.Loss, &ts); err != nil {
+			rows.Close()
+			return err
+		}
+		d.Timestamp = time.UnixMicro(ts)
+
+		for res := range rollupTables {
+			key := bucketKey{d.HostID, bucketStart(d.Timestamp, res)}
+			if p := open[res]; p == nil || p.key != key {
+				closeBucket(res)
+				open[res] = &pending{key: key}
+			}
+			open[res].samples = append(open[res].samples, d)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for res := range rollupTables {
+		closeBucket(res)
+	}
+
+	for _, ru := range done {
+		if err := upsertRollup(tx, ru); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,172 +6,253 @@ import (
 	"lag-monitor/internal/domain"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// SQLiteBatcher grava amostras em lote por um único goroutine escritor e atende
+// leituras (relatórios, histórico) por um pool separado. Com WAL, leituras não
+// bloqueiam a escrita e vice-versa. Retenção, compactação, importação e anotações
+// também rodam no escritor (onWriter): só ele usa a conexão de escrita.
 type SQLiteBatcher struct {
-	db        *sql.DB
+	path      string
+	db        *sql.DB // Conexão única de escrita
+	reader    *sql.DB // Pool somente leitura
 	buffer    []domain.PingResult
 	mu        sync.Mutex
 	batchSize int
 	ticker    *time.Ticker
+	wake      chan struct{} // Acorda o escritor quando o lote enche
+	jobs      chan func()   // Mutações fora do lote, executadas pelo escritor
 	quit      chan struct{}
+	done      chan struct{}
+	rollups   *rollupTracker // Intervalos pendentes de agregação (só o escritor acessa)
+
+	closed   bool  // Após Close, novas amostras são recusadas
+	closeErr error // Resultado do esvaziamento final do buffer
+	health   domain.StorageHealth
+	onError  func(domain.StorageError)
+	legacy   map[string]string // Conteúdo da antiga tabela settings
+
+	dropping   bool      // O buffer está descartando amostras (já avisado)
+	dropUnsent int       // Descartes ainda não avisados
+	dropSentAt time.Time // Último aviso de descarte
 }
 
+const (
+	// auto_vacuum só vale para bancos novos; os existentes são convertidos na migração 7
+	writerDSN = "file:%s?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=5000&_auto_vacuum=incremental"
+	readerDSN = "file:%s?mode=ro&_busy_timeout=5000"
+	readPool  = 4
+)
+
 func NewSQLiteRepo(dbPath string) (*SQLiteBatcher, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	// Uma restauração agendada troca o arquivo antes de qualquer conexão
+	if restored, err := ApplyPendingRestore(dbPath); err != nil {
+		return nil, fmt.Errorf("restaurar snapshot: %w", err)
+	} else if restored {
+		fmt.Println("Banco restaurado a partir de snapshot")
+	}
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf(writerDSN, dbPath))
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(1)
 
-	// Cria tabela se não existir
-	query := `
-	CREATE TABLE IF NOT EXISTS pings (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		host_id TEXT,
-		latency INTEGER,
-		jitter INTEGER,
-		loss BOOLEAN,
-		timestamp DATETIME
-	);`
-	if _, err := db.Exec(query); err != nil {
+	// Lidas antes da migração que remove a tabela, para irem ao settings.json
+	legacy := readLegacySettings(db)
+
+	// Aplica as migrações pendentes (com backup do banco existente)
+	if err := migrate(db, dbPath); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	querySettings := `
-	CREATE TABLE IF NOT EXISTS settings (
-		key TEXT PRIMARY KEY,
-		value TEXT
-	);`
-	if _, err := db.Exec(querySettings); err != nil {
+	// O pool de leitura só é aberto depois das migrações (mode=ro exige o arquivo pronto)
+	reader, err := sql.Open("sqlite3", fmt.Sprintf(readerDSN, dbPath))
+	if err != nil {
+		db.Close()
 		return nil, err
 	}
+	reader.SetMaxOpenConns(readPool)
 
 	repo := &SQLiteBatcher{
+		path:      dbPath,
 		db:        db,
+		reader:    reader,
 		batchSize: 100,                             // Grava a cada 100 registros
 		ticker:    time.NewTicker(5 * time.Second), // OU a cada 5 segundos
+		wake:      make(chan struct{}, 1),
+		jobs:      make(chan func()),
 		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
 		buffer:    make([]domain.PingResult, 0, 100),
+		rollups:   newRollupTracker(),
+		health:    domain.StorageHealth{Healthy: true, Capacity: maxBuffered},
+		legacy:    legacy,
+	}
+
+	if err := repo.catchUpRollups(); err != nil {
+		fmt.Println("Falha ao recuperar agregados recentes:", err)
 	}
 
-	go repo.flushLoop()
+	go repo.writeLoop()
 	return repo, nil
 }
 
-func (r *SQLiteBatcher) SaveBatch(results []domain.PingResult) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+// GetHistory busca registros filtrados por host e data
+func (r *SQLiteBatcher) GetHistory(hostID string, start, end time.Time) ([]domain.PingResult, error) {
+	query := `
+		SELECT host_id, latency, jitter, loss, ts
+		FROM pings
+		WHERE host_id = ? AND ts BETWEEN ? AND ?
+		ORDER BY ts ASC`
 
-	r.buffer = append(r.buffer, results...)
-	if len(r.buffer) >= r.batchSize {
-		go r.flush(r.popBuffer())
+	rows, err := r.reader.Query(query, hostID, start.UnixMicro(), end.UnixMicro())
+	if err != nil {
+		return nil, err
 	}
-	return nil
-}
+	defer rows.Close()
 
-func (r *SQLiteBatcher) flushLoop() {
-	for {
-		select {
-		case <-r.ticker.C:
-			r.mu.Lock()
-			if len(r.buffer) > 0 {
-				data := r.popBuffer()
-				go r.flush(data)
-			}
-			r.mu.Unlock()
-		case <-r.quit:
-			return
+	var results []domain.PingResult
+	for rows.Next() {
+		var res domain.PingResult
+		var ts int64
+		if err := rows.Scan(&res.HostID, &res.Latency, &res.Jitter, &res.Loss, &ts); err != nil {
+			continue
 		}
+		res.Timestamp = time.UnixMicro(ts)
+		results = append(results, res)
 	}
+	return results, rows.Err()
 }
 
-func (r *SQLiteBatcher) popBuffer() []domain.PingResult {
-	tmp := make([]domain.PingResult, len(r.buffer))
-	copy(tmp, r.buffer)
-	r.buffer = r.buffer[:0]
-	return tmp
+// CleanOldData remove amostras mais antigas que o número de dias especificado. Hosts
+// em overrides usam o próprio número de dias.
+func (r *SQLiteBatcher) CleanOldData(days int, overrides map[string]int) (total int64, err error) {
+	err = r.onWriter(func() error {
+		total, err = r.cleanOldData(days, overrides)
+		return err
+	})
+	return total, err
 }
 
-func (r *SQLiteBatcher) flush(data []domain.PingResult) {
-	if len(data) == 0 {
-		return
-	}
-
+func (r *SQLiteBatcher) cleanOldData(days int, overrides map[string]int) (int64, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
-		return
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM pings WHERE ts < ?"
+	args := []interface{}{cutoff(days)}
+	if len(overrides) > 0 {
+		query += " AND host_id NOT IN (?" + strings.Repeat(", ?", len(overrides)-1) + ")"
+		for hostID := range overrides {
+			args = append(args, hostID)
+		}
 	}
 
-	stmt, err := tx.Prepare("INSERT INTO pings(host_id, latency, jitter, loss, timestamp) VALUES(?, ?, ?, ?, ?)")
+	result, err := tx.Exec(query, args...)
 	if err != nil {
-		return
+		return 0, err
 	}
-	defer stmt.Close()
+	total, _ := result.RowsAffected()
 
-	for _, d := range data {
-		stmt.Exec(d.HostID, d.Latency, d.Jitter, d.Loss, d.Timestamp)
+	for hostID, d := range overrides {
+		result, err := tx.Exec("DELETE FROM pings WHERE host_id = ? AND ts < ?", hostID, cutoff(d))
+		if err != nil {
+			return 0, err
+		}
+		n, _ := result.RowsAffected()
+		total += n
 	}
-	tx.Commit()
+	return total, tx.Commit()
 }
 
-func (r *SQLiteBatcher) Close() error {
-	close(r.quit)
-	r.ticker.Stop()
-	// Flush final
-	r.mu.Lock()
-	r.flush(r.buffer)
-	r.mu.Unlock()
-	return r.db.Close()
+func cutoff(days int) int64 {
+	return time.Now().AddDate(0, 0, -days).UnixMicro()
 }
 
-// GetHistory busca registros filtrados por host e data
-func (r *SQLiteBatcher) GetHistory(hostID string, start, end time.Time) ([]domain.PingResult, error) {
-	query := `
-		SELECT host_id, latency, jitter, loss, timestamp 
-		FROM pings 
-		WHERE host_id = ? AND timestamp BETWEEN ? AND ?
-		ORDER BY timestamp ASC`
+// Compact devolve ao sistema as páginas liberadas pela retenção e trunca o WAL. Retorna
+// quantos bytes o banco diminuiu.
+func (r *SQLiteBatcher) Compact() (reclaimed int64, err error) {
+	err = r.onWriter(func() error {
+		reclaimed, err = r.compact()
+		return err
+	})
+	return reclaimed, err
+}
 
-	rows, err := r.db.Query(query, hostID, start, end)
+func (r *SQLiteBatcher) compact() (int64, error) {
+	before, err := databaseSize(r.db)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
+	if err := incrementalVacuum(r.db); err != nil {
+		return 0, err
+	}
+	if _, err := r.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return 0, err
+	}
+	after, err := databaseSize(r.db)
+	if err != nil {
+		return 0, err
+	}
+	return before - after, nil
+}
 
-	var results []domain.PingResult
+// incrementalVacuum percorre todo o resultado do pragma: cada passo libera uma página,
+// então um Exec simples liberaria apenas a primeira
+func incrementalVacuum(db *sql.DB) error {
+	rows, err := db.Query("PRAGMA incremental_vacuum")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 	for rows.Next() {
-		var res domain.PingResult
-		if err := rows.Scan(&res.HostID, &res.Latency, &res.Jitter, &res.Loss, &res.Timestamp); err != nil {
-			continue
-		}
-		results = append(results, res)
 	}
-	return results, nil
+	return rows.Err()
 }
 
-// CleanOldData remove registros mais antigos que o número de dias especificado
-func (r *SQLiteBatcher) CleanOldData(days int) (int64, error) {
-	result, err := r.db.Exec("DELETE FROM pings WHERE timestamp < datetime('now', ?)", fmt.Sprintf("-%d days", days))
-	if err != nil {
+func databaseSize(db *sql.DB) (int64, error) {
+	var pages, size int64
+	if err := db.QueryRow("PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := db.QueryRow("PRAGMA page_size").Scan(&size); err != nil {
 		return 0, err
 	}
-	return result.RowsAffected()
+	return pages * size, nil
 }
 
-// Métodos para gerenciar configurações
-func (r *SQLiteBatcher) SetSetting(key, value string) error {
-	_, err := r.db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)", key, value)
-	return err
+// LegacySettings devolve os pares da antiga tabela settings, removida na migração 6.
+// Vazio em bancos novos ou já migrados.
+func (r *SQLiteBatcher) LegacySettings() map[string]string {
+	return r.legacy
 }
 
-func (r *SQLiteBatcher) GetSetting(key string) (string, error) {
-	var value string
-	err := r.db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
-	return value, err
+// readLegacySettings lê a tabela settings, se ainda existir
+func readLegacySettings(db *sql.DB) map[string]string {
+	rows, err := db.Query("SELECT key, value FROM settings")
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	out := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if rows.Scan(&key, &value) == nil {
+			out[key] = value.String
+		}
+	}
+	return out
 }
 
 func GetDatabasePath() string {
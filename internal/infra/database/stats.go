@@ -0,0 +1,153 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// Percentis pelo método do rank mais próximo: rank = ceil(p/100 * n), em aritmética
+// inteira para não depender das funções matemáticas opcionais do SQLite
+const percentileRank = "MAX(1, (%d * n + 99) / 100)"
+
+// rawSeriesSQL agrega as amostras brutas por intervalo. ?1 é o tamanho do intervalo em
+// microsegundos (0 agrega o período inteiro em um único ponto).
+var rawSeriesSQL = fmt.Sprintf(`
+	WITH s AS (
+		SELECT CASE WHEN ?1 > 0 THEN (ts / ?1) * ?1 ELSE ?3 END AS b, latency, jitter, loss
+		FROM pings
+		WHERE host_id = ?2 AND ts BETWEEN ?3 AND ?4
+	),
+	agg AS (
+		SELECT b, COUNT(*) AS cnt, SUM(loss) AS lost,
+			MIN(CASE WHEN loss = 0 THEN latency END) AS lat_min,
+			AVG(CASE WHEN loss = 0 THEN latency END) AS lat_avg,
+			MAX(CASE WHEN loss = 0 THEN latency END) AS lat_max,
+			AVG(CASE WHEN loss = 0 THEN jitter END) AS jit_avg,
+			MAX(CASE WHEN loss = 0 THEN jitter END) AS jit_max
+		FROM s GROUP BY b
+	),
+	ranked AS (
+		SELECT b, latency,
+			ROW_NUMBER() OVER (PARTITION BY b ORDER BY latency) AS rn,
+			COUNT(*) OVER (PARTITION BY b) AS n
+		FROM s WHERE loss = 0
+	),
+	pct AS (
+		SELECT b,
+			MAX(CASE WHEN rn = %[1]s THEN latency END) AS p50,
+			MAX(CASE WHEN rn = %[2]s THEN latency END) AS p95,
+			MAX(CASE WHEN rn = %[3]s THEN latency END) AS p99
+		FROM ranked GROUP BY b
+	)
+	SELECT agg.b, cnt, lost, lat_min, lat_avg, lat_max, p50, p95, p99, jit_avg, jit_max
+	FROM agg LEFT JOIN pct ON pct.b = agg.b
+	ORDER BY agg.b ASC`,
+	fmt.Sprintf(percentileRank, 50), fmt.Sprintf(percentileRank, 95), fmt.Sprintf(percentileRank, 99))
+
+// rollupSeriesSQL reagrupa um nível de agregados em intervalos maiores. Médias são
+// ponderadas pelos pacotes respondidos; P95/P99 usam o maior valor dos intervalos
+// (limite superior, pois percentis não podem ser recombinados exatamente).
+const rollupSeriesSQL = `
+	SELECT CASE WHEN ?1 > 0 THEN (bucket / ?1) * ?1 ELSE ?3 END AS b,
+		SUM(count), SUM(loss_count),
+		MIN(CASE WHEN count > loss_count THEN lat_min END),
+		SUM(lat_avg * (count - loss_count)) * 1.0 / NULLIF(SUM(count - loss_count), 0),
+		MAX(lat_max),
+		SUM(lat_p50 * (count - loss_count)) / NULLIF(SUM(count - loss_count), 0),
+		MAX(lat_p95), MAX(lat_p99),
+		SUM(jitter_avg * (count - loss_count)) * 1.0 / NULLIF(SUM(count - loss_count), 0),
+		MAX(jitter_max)
+	FROM %s
+	WHERE host_id = ?2 AND bucket BETWEEN ?3 AND ?4
+	GROUP BY b
+	ORDER BY b ASC`
+
+// GetSeries agrega o período do host em intervalos de tamanho bucket, a partir do nível
+// res (amostras brutas ou agregados). Intervalos são alinhados ao epoch, como os agregados.
+func (r *SQLiteBatcher) GetSeries(hostID string, res domain.Resolution, start, end time.Time, bucket time.Duration) ([]domain.Rollup, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("intervalo inválido: %s", bucket)
+	}
+	return r.querySeries(hostID, res, start, end, bucket)
+}
+
+// GetStats resume o período do host em um único ponto, calculado no banco
+func (r *SQLiteBatcher) GetStats(hostID string, res domain.Resolution, start, end time.Time) (domain.RangeStats, error) {
+	points, err := r.querySeries(hostID, res, start, end, 0)
+	if err != nil || len(points) == 0 {
+		return domain.RangeStats{HostID: hostID, Resolution: res, Start: start, End: end}, err
+	}
+	st := domain.StatsFromRollup(points[0], end)
+	st.Start = start
+	return st, nil
+}
+
+func (r *SQLiteBatcher) querySeries(hostID string, res domain.Resolution, start, end time.Time, bucket time.Duration) ([]domain.Rollup, error) {
+	query := rawSeriesSQL
+	if res != domain.ResolutionRaw {
+		table, ok := rollupTables[res]
+		if !ok {
+			return nil, fmt.Errorf("resolução %q não possui agregados", res)
+		}
+		query = fmt.Sprintf(rollupSeriesSQL, table)
+		start = start.Truncate(res.Duration())
+	}
+
+	rows, err := r.reader.Query(query, bucket.Microseconds(), hostID, start.UnixMicro(), end.UnixMicro())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.Rollup
+	for rows.Next() {
+		ru := domain.Rollup{HostID: hostID, Resolution: res}
+		var b int64
+		var lost, latMin, latMax, p50, p95, p99, jitMax sql.NullInt64
+		var latAvg, jitAvg sql.NullFloat64
+		if err := rows.Scan(&b, &ru.Count, &lost, &latMin, &latAvg, &latMax,
+			&p50, &p95, &p99, &jitAvg, &jitMax); err != nil {
+			return nil, err
+		}
+		ru.Bucket = time.UnixMicro(b)
+		ru.LossCount = int(lost.Int64)
+		ru.LatMin, ru.LatMax = latMin.Int64, latMax.Int64
+		ru.LatAvg = int64(latAvg.Float64)
+		ru.LatP50, ru.LatP95, ru.LatP99 = p50.Int64, p95.Int64, p99.Int64
+		ru.JitterAvg, ru.JitterMax = int64(jitAvg.Float64), jitMax.Int64
+		out = append(out, ru)
+	}
+	return out, rows.Err()
+}
+
+// GetHistogram distribui as latências respondidas do período em faixas de binWidth
+// microsegundos. Faixas vazias não são retornadas.
+func (r *SQLiteBatcher) GetHistogram(hostID string, start, end time.Time, binWidth int64) ([]domain.HistogramBin, error) {
+	if binWidth <= 0 {
+		return nil, fmt.Errorf("largura de faixa inválida: %d", binWidth)
+	}
+
+	rows, err := r.reader.Query(`
+		SELECT latency / ? AS bin, COUNT(*)
+		FROM pings
+		WHERE host_id = ? AND ts BETWEEN ? AND ? AND loss = 0
+		GROUP BY bin
+		ORDER BY bin ASC`, binWidth, hostID, start.UnixMicro(), end.UnixMicro())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.HistogramBin
+	for rows.Next() {
+		var bin int64
+		var count int
+		if err := rows.Scan(&bin, &count); err != nil {
+			return nil, err
+		}
+		out = append(out, domain.HistogramBin{From: bin * binWidth, To: (bin + 1) * binWidth, Count: count})
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,285 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+const (
+	// Limite de amostras em memória. Acima dele as mais antigas são descartadas
+	// (política drop-oldest): a série recente vale mais que a antiga quando o disco falha.
+	maxBuffered = 100_000
+
+	// Tentativas imediatas por lote antes de devolvê-lo ao buffer até o próximo ciclo
+	flushAttempts = 3
+	flushBackoff  = 100 * time.Millisecond
+
+	// Tentativas no encerramento, quando não haverá próximo ciclo
+	closeAttempts = 5
+	closeBackoff  = time.Second
+
+	// Com o buffer cheio, intervalo mínimo entre avisos de descarte
+	dropReportEvery = time.Minute
+)
+
+// OnError registra quem recebe as falhas de gravação e os descartes
+func (r *SQLiteBatcher) OnError(fn func(domain.StorageError)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onError = fn
+}
+
+// SaveBatch aceita amostras para gravação em lote. Retorna ErrBufferFull se amostras
+// antigas precisaram ser descartadas e ErrStorageClosed após Close.
+func (r *SQLiteBatcher) SaveBatch(results []domain.PingResult) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return domain.ErrStorageClosed
+	}
+
+	r.buffer = append(r.buffer, results...)
+	dropped := r.enforceLimit()
+	notify := r.dropsToReport(time.Now())
+	if len(r.buffer) >= r.batchSize {
+		select {
+		case r.wake <- struct{}{}:
+		default: // O escritor já foi acordado
+		}
+	}
+	r.mu.Unlock()
+
+	r.reportDrops(notify)
+	if dropped > 0 {
+		return fmt.Errorf("%w: %d amostras antigas descartadas", domain.ErrBufferFull, dropped)
+	}
+	return nil
+}
+
+// enforceLimit descarta as amostras mais antigas acima do limite. Requer r.mu.
+func (r *SQLiteBatcher) enforceLimit() int {
+	excess := len(r.buffer) - maxBuffered
+	if excess <= 0 {
+		return 0
+	}
+	r.buffer = append(r.buffer[:0], r.buffer[excess:]...)
+	r.health.Dropped += int64(excess)
+	r.dropUnsent += excess
+	return excess
+}
+
+// dropsToReport devolve quantos descartes avisar agora: logo no primeiro e, enquanto
+// o buffer seguir cheio, os acumulados a cada dropReportEvery. Requer r.mu.
+func (r *SQLiteBatcher) dropsToReport(now time.Time) int {
+	if r.dropUnsent == 0 || (r.dropping && now.Sub(r.dropSentAt) < dropReportEvery) {
+		return 0
+	}
+	n := r.dropUnsent
+	r.dropping, r.dropUnsent, r.dropSentAt = true, 0, now
+	return n
+}
+
+func (r *SQLiteBatcher) reportDrops(n int) {
+	if n > 0 {
+		r.report("drop", fmt.Errorf("%w: %d amostras antigas descartadas", domain.ErrBufferFull, n), n)
+	}
+}
+
+// writeLoop é o único goroutine que grava no banco: amostras, agregados e as
+// mutações recebidas por onWriter
+func (r *SQLiteBatcher) writeLoop() {
+	defer close(r.done)
+
+	for {
+		select {
+		case <-r.ticker.C:
+		case <-r.wake:
+		case job := <-r.jobs:
+			job()
+			continue
+		case <-r.quit:
+			r.closeErr = r.drain()
+			return
+		}
+		r.writeBatch(flushAttempts, flushBackoff, false)
+	}
+}
+
+// onWriter executa fn no goroutine escritor e espera o resultado. fn não pode chamar
+// onWriter. Após o Close retorna ErrStorageClosed sem executar fn.
+func (r *SQLiteBatcher) onWriter(fn func() error) error {
+	result := make(chan error, 1)
+	select {
+	case r.jobs <- func() { result <- fn() }:
+		return <-result
+	case <-r.done:
+		return domain.ErrStorageClosed
+	}
+}
+
+// drain grava tudo que foi aceito antes do Close
+func (r *SQLiteBatcher) drain() error {
+	var err error
+	for i := 0; i < closeAttempts; i++ {
+		if err = r.writeBatch(1, 0, true); err == nil {
+			return nil
+		}
+		time.Sleep(closeBackoff)
+	}
+
+	lost := len(r.takeBuffer())
+	r.report("close", err, lost)
+	return fmt.Errorf("%d amostras não puderam ser gravadas: %w", lost, err)
+}
+
+// writeBatch grava o buffer atual. Em caso de falha o lote volta para o início do
+// buffer e será tentado no próximo ciclo.
+func (r *SQLiteBatcher) writeBatch(attempts int, backoff time.Duration, final bool) error {
+	data := r.takeBuffer()
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = r.flush(data); err == nil {
+			break
+		}
+		time.Sleep(backoff << i)
+	}
+
+	if err != nil {
+		r.requeue(data)
+		r.report("flush", err, len(data))
+		return err
+	}
+
+	if lost, recovered := r.markFlushed(); recovered {
+		r.report("recover", fmt.Errorf("buffer voltou a aceitar amostras (%d descartadas desde o último aviso)", lost), lost)
+	}
+	r.rollups.mark(data)
+	r.refreshRollups(final)
+	return nil
+}
+
+func (r *SQLiteBatcher) takeBuffer() []domain.PingResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.popBuffer()
+}
+
+func (r *SQLiteBatcher) popBuffer() []domain.PingResult {
+	tmp := make([]domain.PingResult, len(r.buffer))
+	copy(tmp, r.buffer)
+	r.buffer = r.buffer[:0]
+	return tmp
+}
+
+// requeue devolve um lote que falhou para a frente do buffer, respeitando o limite
+func (r *SQLiteBatcher) requeue(data []domain.PingResult) {
+	r.mu.Lock()
+	r.buffer = append(data, r.buffer...)
+	r.enforceLimit()
+	notify := r.dropsToReport(time.Now())
+	r.mu.Unlock()
+
+	r.reportDrops(notify)
+}
+
+// flush grava o lote em uma única transação (tudo ou nada)
+func (r *SQLiteBatcher) flush(data []domain.PingResult) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback() // Sem efeito após o Commit
+
+	stmt, err := tx.Prepare("INSERT OR IGNORE INTO pings(host_id, latency, jitter, loss, ts) VALUES(?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, d := range data {
+		if _, err := stmt.Exec(d.HostID, d.Latency, d.Jitter, d.Loss, d.Timestamp.UnixMicro()); err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// markFlushed registra a gravação. Se o buffer estava descartando e voltou a ter
+// espaço, informa quantos descartes ainda não tinham sido avisados.
+func (r *SQLiteBatcher) markFlushed() (lost int, recovered bool) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.health.Healthy = true
+	r.health.ConsecutiveFailures = 0
+	r.health.LastFlushAt = &now
+
+	if !r.dropping || len(r.buffer) >= maxBuffered {
+		return 0, false
+	}
+	lost = r.dropUnsent
+	r.dropping, r.dropUnsent = false, 0
+	return lost, true
+}
+
+// report registra a falha no estado de saúde e avisa o callback (recover só avisa)
+func (r *SQLiteBatcher) report(op string, err error, samples int) {
+	now := time.Now()
+
+	r.mu.Lock()
+	if op != "recover" {
+		if op != "drop" {
+			r.health.Healthy = false
+			r.health.ConsecutiveFailures++
+		}
+		r.health.LastError = err.Error()
+		r.health.LastErrorAt = &now
+	}
+	fn := r.onError
+	r.mu.Unlock()
+
+	if fn != nil {
+		fn(domain.StorageError{Op: op, Message: err.Error(), Samples: samples, Timestamp: now})
+	}
+}
+
+// Health retorna o estado atual do caminho de escrita
+func (r *SQLiteBatcher) Health() domain.StorageHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h := r.health
+	h.Buffered = len(r.buffer)
+	return h
+}
+
+// Close recusa novas amostras, grava todas as aceitas e fecha o banco. Retorna erro
+// se alguma amostra aceita não pôde ser gravada.
+func (r *SQLiteBatcher) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+
+	r.ticker.Stop()
+	close(r.quit)
+	// Aguarda o escritor gravar o que restou no buffer
+	<-r.done
+
+	return errors.Join(r.closeErr, r.reader.Close(), r.db.Close())
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"lag-monitor/internal/domain"
+)
+
+func TestDropReportsAreRateLimited(t *testing.T) {
+	r := &SQLiteBatcher{}
+	start := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	// drop passa do limite em n amostras e devolve quantas seriam avisadas em at
+	drop := func(n int, at time.Duration) int {
+		r.buffer = make([]domain.PingResult, maxBuffered+n)
+		r.enforceLimit()
+		return r.dropsToReport(start.Add(at))
+	}
+
+	steps := []struct {
+		name   string
+		drop   int
+		at     time.Duration
+		notify int
+	}{
+		{"primeiro descarte avisa", 5, 0, 5},
+		{"descarte seguinte acumula", 3, time.Second, 0},
+		{"ainda dentro do intervalo", 4, 30 * time.Second, 0},
+		{"intervalo vencido avisa o acumulado", 2, dropReportEvery, 9},
+		{"volta a acumular", 1, dropReportEvery + time.Second, 0},
+	}
+	for _, st := range steps {
+		if got := drop(st.drop, st.at); got != st.notify {
+			t.Fatalf("%s: avisados = %d, esperado %d", st.name, got, st.notify)
+		}
+	}
+
+	// Com o buffer cheio a gravação não encerra o estado de descarte
+	if _, recovered := r.markFlushed(); recovered {
+		t.Fatal("buffer ainda cheio foi dado como recuperado")
+	}
+
+	r.buffer = r.buffer[:0]
+	lost, recovered := r.markFlushed()
+	if !recovered || lost != 1 {
+		t.Fatalf("recuperação: %v com %d descartes pendentes; esperado true e 1", recovered, lost)
+	}
+	if _, again := r.markFlushed(); again {
+		t.Error("recuperação avisada duas vezes")
+	}
+	if r.health.Dropped != 15 {
+		t.Errorf("total descartado = %d, esperado 15", r.health.Dropped)
+	}
+
+	// Um novo episódio avisa de imediato
+	if got := drop(2, dropReportEvery+2*time.Second); got != 2 {
+		t.Errorf("novo episódio: avisados = %d, esperado 2", got)
+	}
+}
+
+func TestMutationsRunOnWriter(t *testing.T) {
+	repo, err := NewSQLiteRepo(filepath.Join(t.TempDir(), "lagmonitor.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Gravações em lote, importação, anotações e retenção concorrendo pela conexão de escrita
+	now := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				d := domain.PingResult{HostID: "a", Latency: 10_000, Timestamp: now.Add(time.Duration(i*1000+j) * time.Millisecond)}
+				if i%2 == 0 {
+					repo.SaveBatch([]domain.PingResult{d})
+					continue
+				}
+				if _, err := repo.Import([]domain.PingResult{d}, time.Microsecond); err != nil {
+					t.Errorf("import: %v", err)
+				}
+				if _, err := repo.AddAnnotation(domain.Annotation{Start: d.Timestamp, Text: "x", Kind: domain.AnnotationUser, CreatedAt: now}); err != nil {
+					t.Errorf("anotação: %v", err)
+				}
+				if _, err := repo.CleanOldData(30, nil); err != nil {
+					t.Errorf("retenção: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := repo.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CleanOldData(30, nil); !errors.Is(err, domain.ErrStorageClosed) {
+		t.Errorf("retenção após Close: %v, esperado ErrStorageClosed", err)
+	}
+	if _, err := repo.Compact(); !errors.Is(err, domain.ErrStorageClosed) {
+		t.Errorf("compactação após Close: %v, esperado ErrStorageClosed", err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package hooks
+
+import "os/exec"
+
+// killGroup no Windows mantém o padrão (encerra o processo); WaitDelay libera o Run
+// quando filhos seguram a saída
+func killGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,297 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"lag-monitor/internal/config"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	maxOutputBytes = 64 * 1024
+	// Após o timeout, quanto esperar os processos filhos liberarem a saída
+	waitDelay = 2 * time.Second
+	// Eventos aguardando por hook enquanto ele ainda está rodando
+	maxQueued = 16
+)
+
+// Events lista os eventos que podem disparar hooks. Eventos frequentes (ping:data) e os
+// do próprio runner (hook:result) ficam de fora, mesmo com "*".
+var Events = []string{
+	"incident:open", "incident:close", "incident:planned", "incident:suppressed",
+	"alert:triggered", "alert:resolved", "rootcause:detected", "anomaly:detected",
+	"network:change", "route:change",
+	"storage:error", "backup:created", "backup:failed",
+}
+
+func hookable(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Result descreve uma execução de hook
+type Result struct {
+	Event      string    `json:"event"`
+	Command    string    `json:"command"`
+	ExitCode   int       `json:"exitCode"`
+	Stdout     string    `json:"stdout"`
+	Stderr     string    `json:"stderr"`
+	Error      string    `json:"error,omitempty"`
+	TimedOut   bool      `json:"timedOut"`
+	Skipped    bool      `json:"skipped"` // Descartado com a fila do hook cheia, sem executar
+	DurationMs int64     `json:"durationMs"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// Runner executa comandos configurados pelo usuário quando eventos ocorrem
+type Runner struct {
+	hooks    func() []config.HookConfig
+	onResult func(Result)
+
+	mu     sync.Mutex
+	queues map[string][]invocation // Hooks em execução (evento + comando) e o que espera por eles
+}
+
+// invocation é um disparo de hook aguardando execução
+type invocation struct {
+	hook    config.HookConfig
+	event   string
+	payload []byte
+	env     []string
+}
+
+// NewRunner recebe uma função que fornece os hooks atuais (lidos a cada evento)
+func NewRunner(provider func() []config.HookConfig) *Runner {
+	return &Runner{hooks: provider, queues: map[string][]invocation{}}
+}
+
+// OnResult registra um callback chamado ao fim de cada execução
+func (r *Runner) OnResult(fn func(Result)) {
+	r.onResult = fn
+}
+
+// Dispatch dispara, em background, todos os hooks que casam com o evento. Cada hook roda
+// uma execução por vez: eventos que chegam enquanto ele roda entram na fila dele, e com a
+// fila cheia são descartados com um Result marcado como Skipped.
+func (r *Runner) Dispatch(event string, data interface{}) {
+	if r.hooks == nil || !hookable(event) {
+		return
+	}
+
+	var matched []config.HookConfig
+	for _, h := range r.hooks() {
+		if h.Enabled && h.Command != "" && matches(h.Event, event) {
+			matched = append(matched, h)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":     event,
+		"timestamp": time.Now(),
+		"data":      data,
+	})
+	if err != nil {
+		return
+	}
+	env := buildEnv(event, data)
+
+	for _, h := range matched {
+		inv := invocation{hook: h, event: event, payload: payload, env: env}
+		key := h.Event + "\x00" + h.Command
+
+		r.mu.Lock()
+		queue, busy := r.queues[key]
+		switch {
+		case !busy:
+			r.queues[key] = nil
+			go r.drain(key, inv)
+		case len(queue) < maxQueued:
+			r.queues[key] = append(queue, inv)
+		default:
+			r.mu.Unlock()
+			r.report(Result{
+				Event:     event,
+				Command:   h.Command,
+				Skipped:   true,
+				Error:     fmt.Sprintf("%d eventos já aguardam o hook; evento descartado", maxQueued),
+				Timestamp: time.Now(),
+			})
+			continue
+		}
+		r.mu.Unlock()
+	}
+}
+
+// drain executa inv e depois o que tiver entrado na fila do hook, em ordem
+func (r *Runner) drain(key string, inv invocation) {
+	for {
+		r.run(inv.hook, inv.event, inv.payload, inv.env)
+
+		r.mu.Lock()
+		queue := r.queues[key]
+		if len(queue) == 0 {
+			delete(r.queues, key)
+			r.mu.Unlock()
+			return
+		}
+		inv, r.queues[key] = queue[0], queue[1:]
+		r.mu.Unlock()
+	}
+}
+
+func (r *Runner) report(res Result) {
+	if r.onResult != nil {
+		r.onResult(res)
+	}
+}
+
+func (r *Runner) run(h config.HookConfig, event string, payload []byte, env []string) {
+	timeout := defaultTimeout
+	if h.TimeoutSec > 0 {
+		timeout = time.Duration(h.TimeoutSec) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, h.Command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(payload)
+
+	stdout := &limitedBuffer{max: maxOutputBytes}
+	stderr := &limitedBuffer{max: maxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err := cmd.Run()
+
+	res := Result{
+		Event:      event,
+		Command:    h.Command,
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		DurationMs: time.Since(start).Milliseconds(),
+		Timestamp:  start,
+	}
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		res.TimedOut = true
+		err = fmt.Errorf("timeout após %s", timeout)
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	r.report(res)
+}
+
+// shellCommand roda o comando no shell do sistema. No timeout o grupo de processos
+// inteiro é encerrado, e WaitDelay impede que filhos segurando a saída travem o Run.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	killGroup(cmd)
+	cmd.WaitDelay = waitDelay
+	return cmd
+}
+
+// matches aceita nome exato, "*" ou prefixo terminado em "*" (ex: "incident:*")
+func matches(pattern, event string) bool {
+	if pattern == "*" || pattern == event {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(event, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// buildEnv achata os campos escalares do evento em variáveis LAGMON_*
+func buildEnv(event string, data interface{}) []string {
+	env := []string{
+		"LAGMON_EVENT=" + event,
+		"LAGMON_TIMESTAMP=" + time.Now().Format(time.RFC3339),
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return env
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return env
+	}
+
+	for k, v := range fields {
+		switch val := v.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			continue
+		case float64:
+			env = append(env, fmt.Sprintf("LAGMON_%s=%s", envName(k), strconv.FormatFloat(val, 'f', -1, 64)))
+		default:
+			env = append(env, fmt.Sprintf("LAGMON_%s=%v", envName(k), val))
+		}
+	}
+	return env
+}
+
+// envName converte "hostId" em "HOST_ID"
+func envName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			r = '_'
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// limitedBuffer descarta a saída excedente para não crescer indefinidamente
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (l *limitedBuffer) Write(p []byte) (int, error) {
+	if room := l.max - l.buf.Len(); room > 0 {
+		if len(p) > room {
+			l.buf.Write(p[:room])
+		} else {
+			l.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (l *limitedBuffer) String() string {
+	return l.buf.String()
+}
@@ -0,0 +1,89 @@
+package hooks
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"lag-monitor/internal/config"
+)
+
+func TestDispatchQueuesWhileRunning(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("usa sh")
+	}
+
+	tests := []struct {
+		name    string
+		events  int
+		ran     int
+		skipped int
+	}{
+		{"um evento", 1, 1, 0},
+		{"eventos durante a execução entram na fila", 4, 4, 0},
+		// Um rodando, maxQueued na fila e o resto descartado
+		{"fila cheia", maxQueued + 3, maxQueued + 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			r := NewRunner(func() []config.HookConfig {
+				return []config.HookConfig{{Event: "incident:*", Command: "cat > /dev/null", Enabled: true}}
+			})
+
+			var mu sync.Mutex
+			var ran, skipped int
+			var wg sync.WaitGroup
+			wg.Add(tt.events)
+			first := true
+			r.OnResult(func(res Result) {
+				mu.Lock()
+				if res.Skipped {
+					skipped++
+				} else {
+					ran++
+				}
+				// Segura a primeira execução até todos os eventos serem despachados
+				wait := first && !res.Skipped
+				first = first && res.Skipped
+				mu.Unlock()
+				if wait {
+					<-release
+				}
+				wg.Done()
+			})
+
+			for i := 0; i < tt.events; i++ {
+				r.Dispatch("incident:open", map[string]interface{}{"hostId": "h"})
+			}
+			close(release)
+
+			done := make(chan struct{})
+			go func() { wg.Wait(); close(done) }()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("hooks não terminaram")
+			}
+
+			if ran != tt.ran || skipped != tt.skipped {
+				t.Errorf("executados = %d, descartados = %d; esperado %d e %d", ran, skipped, tt.ran, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestDispatchIgnoresUnhookableEvents(t *testing.T) {
+	called := false
+	r := NewRunner(func() []config.HookConfig {
+		return []config.HookConfig{{Event: "*", Command: "true", Enabled: true}}
+	})
+	r.OnResult(func(Result) { called = true })
+
+	r.Dispatch("ping:data", nil)
+	r.Dispatch("hook:result", nil)
+	if called {
+		t.Error("eventos fora da lista não deveriam disparar hooks")
+	}
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"lag-monitor/internal/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Capacidade padrão por host: 24h de amostras a um ping por segundo
+const DefaultSamples = 24 * 60 * 60
+
+// Store guarda as amostras em um buffer circular por host, sem persistência. Serve para
+// execuções efêmeras/portáteis e testes. Os agregados são calculados sob demanda a
+// partir das amostras, então valem apenas para o período que ainda está no buffer.
+type Store struct {
+	mu       sync.RWMutex
+	capacity int
+	hosts    map[string]*ring
+	closed   bool
+
+	annotations []domain.Annotation
+	nextID      int64
+}
+
+func NewStore(samplesPerHost int) *Store {
+	if samplesPerHost <= 0 {
+		samplesPerHost = DefaultSamples
+	}
+	return &Store{capacity: samplesPerHost, hosts: make(map[string]*ring)}
+}
+
+// ring mantém as últimas amostras de um host em ordem de chegada
+type ring struct {
+	buf  []domain.PingResult
+	head int // Posição da amostra mais antiga
+}
+
+func (r *ring) push(d domain.PingResult, capacity int) {
+	if len(r.buf) < capacity {
+		r.buf = append(r.buf, d)
+		return
+	}
+	r.buf[r.head] = d
+	r.head = (r.head + 1) % len(r.buf)
+}
+
+func (r *ring) at(i int) domain.PingResult {
+	return r.buf[(r.head+i)%len(r.buf)]
+}
+
+// between copia as amostras do período (as amostras chegam em ordem cronológica)
+func (r *ring) between(start, end time.Time) []domain.PingResult {
+	n := len(r.buf)
+	first := sort.Search(n, func(i int) bool { return !r.at(i).Timestamp.Before(start) })
+
+	var out []domain.PingResult
+	for i := first; i < n; i++ {
+		d := r.at(i)
+		if d.Timestamp.After(end) {
+			break
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// dropBefore descarta as amostras anteriores ao corte e retorna quantas saíram
+func (r *ring) dropBefore(cutoff time.Time) int {
+	n := len(r.buf)
+	keep := n - sort.Search(n, func(i int) bool { return !r.at(i).Timestamp.Before(cutoff) })
+	if keep == n {
+		return 0
+	}
+
+	kept := make([]domain.PingResult, keep)
+	for i := range kept {
+		kept[i] = r.at(n - keep + i)
+	}
+	r.buf, r.head = kept, 0
+	return n - keep
+}
+
+func (s *Store) SaveBatch(results []domain.PingResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return domain.ErrStorageClosed
+	}
+	for _, d := range results {
+		r, ok := s.hosts[d.HostID]
+		if !ok {
+			r = &ring{}
+			s.hosts[d.HostID] = r
+		}
+		r.push(d, s.capacity)
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *Store) GetHistory(hostID string, start, end time.Time) ([]domain.PingResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.hosts[hostID]
+	if !ok {
+		return nil, nil
+	}
+	return r.between(start, end), nil
+}
+
+// GetRollups calcula os agregados do nível a partir das amostras em memória
+func (s *Store) GetRollups(hostID string, res domain.Resolution, start, end time.Time) ([]domain.Rollup, error) {
+	data, _ := s.GetHistory(hostID, domain.BucketStart(start, res.Duration()), end)
+	rollups := domain.Series(hostID, data, start, res.Duration())
+	for i := range rollups {
+		rollups[i].Resolution = res
+	}
+	return rollups, nil
+}
+
+// GetStats sempre usa as amostras brutas: não há níveis agregados armazenados
+func (s *Store) GetStats(hostID string, res domain.Resolution, start, end time.Time) (domain.RangeStats, error) {
+	data, _ := s.GetHistory(hostID, start, end)
+	return domain.RangeStatsOf(hostID, data, start, end), nil
+}
+
+func (s *Store) GetSeries(hostID string, res domain.Resolution, start, end time.Time, bucket time.Duration) ([]domain.Rollup, error) {
+	data, _ := s.GetHistory(hostID, start, end)
+	return domain.Series(hostID, data, start, bucket), nil
+}
+
+func (s *Store) GetHistogram(hostID string, start, end time.Time, binWidth int64) ([]domain.HistogramBin, error) {
+	data, _ := s.GetHistory(hostID, start, end)
+	return domain.Histogram(data, binWidth), nil
+}
+
+func (s *Store) CleanOldData(days int, overrides map[string]int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var total int64
+	for hostID, r := range s.hosts {
+		d := days
+		if o, ok := overrides[hostID]; ok {
+			d = o
+		}
+		total += int64(r.dropBefore(time.Now().AddDate(0, 0, -d)))
+	}
+	return total, nil
+}
+
+// CleanOldRollups não remove nada: os agregados não são armazenados
+func (s *Store) CleanOldRollups(res domain.Resolution, days int) (int64, error) {
+	return 0, nil
+}
+
+func (s *Store) Compact() (int64, error) {
+	return 0, nil
+}
+
+func (s *Store) AddAnnotation(a domain.Annotation) (domain.Annotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	a.ID = s.nextID
+	s.annotations = append(s.annotations, a)
+	return a, nil
+}
+
+func (s *Store) ListAnnotations(hostID string, start, end time.Time) ([]domain.Annotation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return domain.FilterAnnotations(s.annotations, hostID, start, end), nil
+}
+
+func (s *Store) DeleteAnnotation(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, a := range s.annotations {
+		if a.ID == id {
+			s.annotations = append(s.annotations[:i], s.annotations[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrAnnotationNotFound
+}
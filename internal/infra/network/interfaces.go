@@ -0,0 +1,47 @@
+package network
+
+import (
+	"net"
+	"sort"
+)
+
+// InterfaceProbe lista os endereços das interfaces de rede ativas
+type InterfaceProbe struct{}
+
+func NewInterfaceProbe() *InterfaceProbe {
+	return &InterfaceProbe{}
+}
+
+// LocalAddresses retorna os endereços de cada interface ativa, ignorando loopback e
+// endereços link-local (mudam sem que a rede mude)
+func (p *InterfaceProbe) LocalAddresses() (map[string][]string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]string)
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		var ips []string
+		for _, a := range addrs {
+			ipnet, ok := a.(*net.IPNet)
+			if !ok || ipnet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			ips = append(ips, ipnet.IP.String())
+		}
+		if len(ips) > 0 {
+			sort.Strings(ips)
+			out[iface.Name] = ips
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// DefaultGateway retorna o gateway da rota padrão IPv4 ("" sem rota padrão)
+func (p *InterfaceProbe) DefaultGateway() (string, error) {
+	switch runtime.GOOS {
+	case "linux":
+		data, err := os.ReadFile("/proc/net/route")
+		if err != nil {
+			return "", err
+		}
+		return linuxGateway(data), nil
+	case "darwin", "freebsd":
+		out, err := exec.Command("route", "-n", "get", "default").Output()
+		if err != nil {
+			return "", nil // Sem rota padrão o comando falha
+		}
+		return fieldAfter(out, "gateway:"), nil
+	case "windows":
+		out, err := exec.Command("route", "print", "-4", "0.0.0.0").Output()
+		if err != nil {
+			return "", err
+		}
+		return windowsGateway(out), nil
+	}
+	return "", fmt.Errorf("rota padrão não suportada em %s", runtime.GOOS)
+}
+
+// linuxGateway lê /proc/net/route: destino 00000000 é a rota padrão, com o gateway em
+// hexadecimal na ordem do host (little-endian nas arquiteturas suportadas)
+func linuxGateway(data []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) < 3 || f[1] != "00000000" {
+			continue
+		}
+		raw, err := hex.DecodeString(f[2])
+		if err != nil || len(raw) != 4 {
+			continue
+		}
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(raw))
+		return ip.String()
+	}
+	return ""
+}
+
+// windowsGateway lê a tabela de "route print": "0.0.0.0  0.0.0.0  <gateway>  <interface>  <métrica>"
+func windowsGateway(out []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) >= 3 && f[0] == "0.0.0.0" && f[1] == "0.0.0.0" && net.ParseIP(f[2]) != nil {
+			return f[2]
+		}
+	}
+	return ""
+}
+
+func fieldAfter(out []byte, label string) string {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, label) {
+			return strings.TrimSpace(strings.TrimPrefix(line, label))
+		}
+	}
+	return ""
+}
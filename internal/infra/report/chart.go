@@ -0,0 +1,169 @@
+package report
+
+import (
+	"lag-monitor/internal/domain"
+	"math"
+	"sort"
+	"time"
+)
+
+// Escalas e agrupamentos comuns aos gráficos em SVG (HTML) e em PDF
+
+// plot converte valores em coordenadas dentro da área útil de um gráfico
+type plot struct {
+	left, top, width, height float64
+	start, end               time.Time
+	yMax                     float64
+}
+
+func (p plot) x(t time.Time) float64 {
+	span := p.end.Sub(p.start).Seconds()
+	if span <= 0 {
+		return p.left
+	}
+	frac := t.Sub(p.start).Seconds() / span
+	return p.left + math.Max(0, math.Min(1, frac))*p.width
+}
+
+func (p plot) y(v float64) float64 {
+	if p.yMax <= 0 {
+		return p.bottom()
+	}
+	return p.bottom() - math.Min(v, p.yMax)/p.yMax*p.height
+}
+
+func (p plot) bottom() float64 { return p.top + p.height }
+
+// tickTime é o horário do i-ésimo de n intervalos do eixo X
+func (p plot) tickTime(i, n int) time.Time {
+	return p.start.Add(time.Duration(float64(p.end.Sub(p.start)) * float64(i) / float64(n)))
+}
+
+func (p plot) timeLayout() string {
+	if p.end.Sub(p.start) <= 24*time.Hour {
+		return "15:04"
+	}
+	return "02/01 15:04"
+}
+
+// niceCeil arredonda para cima até 1, 2 ou 5 × 10^n
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*exp >= v {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+// answered indica se o intervalo teve alguma resposta (tem latência)
+func answered(pt domain.SeriesPoint) bool {
+	return pt.Count > 0 && pt.LossRatio < 1
+}
+
+// latencyScale é o topo do eixo de latência. Ignora os 2% de máximos mais altos para
+// um pico isolado não achatar o gráfico.
+func latencyScale(series domain.HostSeries) float64 {
+	var peaks []float64
+	for _, pt := range series.Points {
+		if answered(pt) {
+			peaks = append(peaks, float64(pt.LatMax))
+		}
+	}
+	return peakScale(peaks)
+}
+
+// peakScale arredonda o percentil 98 dos valores para o topo do eixo (0 sem valores)
+func peakScale(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	return niceCeil(values[int(float64(len(values)-1)*0.98)])
+}
+
+// latencySegments divide os intervalos com resposta em trechos contínuos: uma falta
+// maior que dois intervalos (app fechado, queda total) interrompe a linha
+func latencySegments(series domain.HostSeries) [][]domain.SeriesPoint {
+	return segments(series, answered)
+}
+
+// segments agrupa em trechos contínuos os intervalos aceitos por keep
+func segments(series domain.HostSeries, keep func(domain.SeriesPoint) bool) [][]domain.SeriesPoint {
+	gap := 2 * time.Duration(series.BucketSec) * time.Second
+	var out [][]domain.SeriesPoint
+	var cur []domain.SeriesPoint
+	for _, pt := range series.Points {
+		if !keep(pt) {
+			continue
+		}
+		if len(cur) > 0 && pt.Time.Sub(cur[len(cur)-1].Time) > gap {
+			out = append(out, cur)
+			cur = nil
+		}
+		cur = append(cur, pt)
+	}
+	if len(cur) > 0 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// histLayout posiciona as faixas do histograma: largura de cada faixa, quantidade de
+// posições no eixo e se a última faixa agrupa as latências acima do limite
+type histLayout struct {
+	step     int64
+	slots    int
+	overflow bool
+	yMax     float64
+}
+
+func layoutHistogram(bins []domain.HistogramBin) histLayout {
+	step := bins[0].To - bins[0].From
+	last := bins[len(bins)-1]
+	maxCount := 0
+	for _, bin := range bins {
+		maxCount = max(maxCount, bin.Count)
+	}
+	return histLayout{
+		step:     step,
+		slots:    int(last.From/step) + 1,
+		overflow: last.To-last.From > step,
+		yMax:     niceCeil(float64(maxCount)),
+	}
+}
+
+// Ordem das linhas do mapa de calor (segunda a domingo)
+var heatWeekdays = []struct {
+	day   time.Weekday
+	label string
+}{
+	{time.Monday, "Seg"}, {time.Tuesday, "Ter"}, {time.Wednesday, "Qua"}, {time.Thursday, "Qui"},
+	{time.Friday, "Sex"}, {time.Saturday, "Sáb"}, {time.Sunday, "Dom"},
+}
+
+// heatGrid indexa as células por dia e hora e calcula a faixa de latências médias
+func heatGrid(cells []domain.HeatCell) (map[[2]int]domain.HeatCell, int64, int64) {
+	byKey := map[[2]int]domain.HeatCell{}
+	lo, hi := int64(math.MaxInt64), int64(0)
+	for _, c := range cells {
+		byKey[[2]int{int(c.Weekday), c.Hour}] = c
+		if c.LatAvg > 0 {
+			lo, hi = min(lo, c.LatAvg), max(hi, c.LatAvg)
+		}
+	}
+	return byKey, lo, hi
+}
+
+// heatHue vai de 120 (verde, menor média) a 0 (vermelho, maior média)
+func heatHue(v, lo, hi int64) float64 {
+	f := 0.0
+	if hi > lo {
+		f = float64(v-lo) / float64(hi-lo)
+	}
+	return 120 * (1 - f)
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"lag-monitor/internal/domain"
+)
+
+// ComparisonHTML grava o comparativo entre hosts como uma página única: indicadores lado
+// a lado, gráficos sobrepostos e os períodos de perda simultânea
+func ComparisonHTML(w io.Writer, cmp domain.ComparisonData) error {
+	series := make([]domain.HostSeries, len(cmp.Hosts))
+	names := make([]string, len(cmp.Hosts))
+	for i, h := range cmp.Hosts {
+		series[i] = h.Series
+		names[i] = h.HostID
+		if h.HostName != "" && h.HostName != h.HostID {
+			names[i] += " (" + h.HostName + ")"
+		}
+	}
+	return comparisonTemplate.Execute(w, comparisonView{
+		ComparisonData: cmp,
+		Legend:         Legend(names),
+		Latency:        CompareLatencyChart(series, cmp.Start, cmp.End),
+		Loss:           CompareLossChart(series, cmp.Start, cmp.End),
+	})
+}
+
+type comparisonView struct {
+	domain.ComparisonData
+	Legend, Latency, Loss template.HTML
+}
+
+var comparisonTemplate = template.Must(template.New("comparison").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Comparativo de qualidade de internet</title>
+` + reportStyle + `</head>
+<body>
+<h1>Comparativo de qualidade de internet</h1>
+<div class="meta">
+  Destinos: {{range $i, $h := .Hosts}}{{if $i}}, {{end}}<b>{{$h.HostID}}</b>{{end}}<br>
+  Período: {{date .Start}} até {{date .End}}<br>
+  Gerado em {{date .GeneratedAt}}
+</div>
+
+<section>
+<h2>Indicadores</h2>
+<table>
+<tr><th></th>{{range .Hosts}}<th>{{.HostID}}</th>{{end}}</tr>
+<tr><td>Status</td>{{range .Hosts}}<td class="{{statusClass .Status}}">{{.Status}}</td>{{end}}</tr>
+<tr><td>Latência média</td>{{range .Hosts}}<td>{{ms .Stats.LatAvg}}</td>{{end}}</tr>
+<tr><td>95% do tempo abaixo de</td>{{range .Hosts}}<td>{{ms .Stats.LatP95}}</td>{{end}}</tr>
+<tr><td>Pior 1% (P99)</td>{{range .Hosts}}<td>{{ms .Stats.LatP99}}</td>{{end}}</tr>
+<tr><td>Jitter médio</td>{{range .Hosts}}<td>{{ms .Stats.JitterAvg}}</td>{{end}}</tr>
+<tr><td>Perda de pacotes</td>{{range .Hosts}}<td>{{pct .Stats.LossPct}}</td>{{end}}</tr>
+<tr><td>Disponibilidade</td>{{range .Hosts}}<td>{{with .SLA}}{{pct .Availability}}{{else}}{{pct (slaFromLoss .Stats.LossPct)}}{{end}}</td>{{end}}</tr>
+<tr><td>Quedas</td>{{range .Hosts}}<td>{{with .SLA}}{{.Outages}}{{else}}–{{end}}</td>{{end}}</tr>
+<tr><td>Incidentes</td>{{range .Hosts}}<td>{{if .SLA}}{{len .Incidents}}{{else}}–{{end}}</td>{{end}}</tr>
+<tr><td>Amostras</td>{{range .Hosts}}<td>{{.Stats.Count}}</td>{{end}}</tr>
+</table>
+<p class="note">Fonte: {{with index .Hosts 0}}{{resolution .Resolution}}{{if not .SLA}} (quedas e incidentes exigem as amostras individuais){{end}}{{end}}.</p>
+</section>
+
+<section>
+<h2>Latência média</h2>
+{{.Legend}}
+{{.Latency}}
+</section>
+
+<section>
+<h2>Perda de pacotes</h2>
+{{.Legend}}
+{{.Loss}}
+</section>
+
+<section>
+<h2>Perdas em comum</h2>
+<table>
+<tr><th>Quando perde…</th>{{range .Hosts}}<th>{{.HostID}} também perde</th>{{end}}</tr>
+{{range $i, $h := .Hosts}}<tr><td>{{$h.HostID}}</td>{{range $j, $v := index $.Overlap $i}}<td>{{if eq $i $j}}–{{else}}{{printf "%.0f%%" $v}}{{end}}</td>{{end}}</tr>
+{{end}}</table>
+<p class="note">Percentual dos intervalos com perda (2% ou mais) de cada host em que o outro host também perdeu.
+Perdas em todos os destinos ao mesmo tempo apontam para o trecho comum (rede local, modem ou operadora);
+perdas em apenas um destino apontam para o caminho até ele.</p>
+</section>
+
+<section>
+<h2>Períodos de perda simultânea</h2>
+{{if .LossPeriods}}<table>
+<tr><th>Início</th><th>Fim</th><th>Hosts (maior perda no trecho)</th></tr>
+{{range .LossPeriods}}{{$p := .}}<tr><td>{{date .Start}}</td><td>{{date .End}}</td><td>{{range $i, $id := .HostIDs}}{{if $i}}, {{end}}{{$id}} ({{printf "%.0f%%" (index $p.PeakPct $id)}}){{end}}</td></tr>
+{{end}}</table>{{else}}<p>Nenhuma perda simultânea em dois ou mais hosts no período.</p>{{end}}
+</section>
+</body>
+</html>
+`))
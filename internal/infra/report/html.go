@@ -0,0 +1,245 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// HTML grava o relatório como uma página única, sem dependências externas (estilos e
+// gráficos SVG embutidos), pronta para abrir no navegador e imprimir
+func HTML(w io.Writer, rep domain.ReportData) error {
+	return htmlTemplate.Execute(w, htmlView{
+		ReportData: rep,
+		Latency:    LatencyChart(rep.Series, rep.Start, rep.End, rep.Annotations),
+		Loss:       LossChart(rep.Series, rep.Start, rep.End),
+		Histogram:  HistogramChart(rep.Histogram),
+		Heatmap:    Heatmap(rep.Heatmap),
+	})
+}
+
+type htmlView struct {
+	domain.ReportData
+	Latency, Loss, Histogram, Heatmap template.HTML
+}
+
+// reportFuncs são as funções dos modelos HTML e Markdown
+var reportFuncs = template.FuncMap{
+	"ms":               func(us int64) string { return fmt.Sprintf("%d ms", us/1000) },
+	"pct":              func(v float64) string { return fmt.Sprintf("%.2f%%", v) },
+	"date":             func(t time.Time) string { return t.Format("02/01/2006 15:04") },
+	"secs":             secs,
+	"incidentDuration": incidentDuration,
+	"statusClass":      statusClass,
+	"resolution":       resolutionLabel,
+	"slaFromLoss":      func(lossPct float64) float64 { return 100 - lossPct },
+	"kind":             kindLabel,
+	"slaDefinition":    slaDefinition,
+	"slaEstimated":     func() string { return slaEstimatedNote },
+	"slaVerdict":       slaVerdict,
+	"md":               mdEscaper.Replace,
+	"signed":           func(v float64) string { return fmt.Sprintf("%+.1f", v) },
+	"pvalue":           pvalue,
+	"verdictClass":     verdictClass,
+	"causePct": func(n, total int) string {
+		if total == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", float64(n)/float64(total)*100)
+	},
+}
+
+func date(t time.Time) string {
+	return t.Format("02/01/2006 15:04")
+}
+
+func secs(sec float64) string {
+	return time.Duration(sec * float64(time.Second)).Round(time.Second).String()
+}
+
+func incidentDuration(i domain.Incident) string {
+	if i.End == nil {
+		return "em aberto"
+	}
+	return i.End.Sub(i.Start).Round(time.Second).String()
+}
+
+// statusClass agrupa o veredito em ok, warn ou bad (cores do destaque)
+func statusClass(status string) string {
+	switch status {
+	case "EXCELENTE":
+		return "ok"
+	case "INSTÁVEL":
+		return "warn"
+	}
+	return "bad"
+}
+
+// pvalue formata o p-valor de um teste ("–" quando não houve teste)
+func pvalue(p float64) string {
+	switch {
+	case p < 0:
+		return "–"
+	case p < 0.001:
+		return "p < 0.001"
+	}
+	return fmt.Sprintf("p = %.3f", p)
+}
+
+// verdictClass colore a variação de um indicador pelo veredito do teste
+func verdictClass(d domain.MetricDelta) string {
+	switch d.Verdict() {
+	case "melhorou":
+		return "ok"
+	case "piorou":
+		return "bad"
+	}
+	return ""
+}
+
+func resolutionLabel(r domain.Resolution) string {
+	switch r {
+	case domain.ResolutionMinute:
+		return "agregados por minuto"
+	case domain.ResolutionHour:
+		return "agregados por hora"
+	}
+	return "amostras individuais"
+}
+
+func kindLabel(kind string) string {
+	switch kind {
+	case domain.AnnotationNetwork:
+		return "rede"
+	case domain.AnnotationConfig:
+		return "configuração"
+	}
+	return "nota"
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Relatório de qualidade de internet – {{.HostID}}</title>
+` + reportStyle + `</head>
+<body>
+<h1>Relatório de qualidade de internet</h1>
+<div class="meta">
+  Destino: <b>{{.HostID}}</b>{{if and .HostName (ne .HostName .HostID)}} ({{.HostName}}){{end}}<br>
+  Período: {{date .Start}} até {{date .End}} · Fonte: {{resolution .Resolution}}<br>
+  Gerado em {{date .GeneratedAt}}
+</div>
+<div class="verdict {{statusClass .Status}}">Status da conexão: {{.Status}}</div>
+
+<section>
+<h2>Resumo</h2>
+<div class="cards">
+  <div class="card"><span>Latência média</span><b>{{ms .Stats.LatAvg}}</b></div>
+  <div class="card"><span>95% do tempo abaixo de</span><b>{{ms .Stats.LatP95}}</b></div>
+  <div class="card"><span>Pior 1% (P99)</span><b>{{ms .Stats.LatP99}}</b></div>
+  <div class="card"><span>Jitter médio</span><b>{{ms .Stats.JitterAvg}}</b></div>
+  <div class="card"><span>Perda de pacotes</span><b>{{pct .Stats.LossPct}}</b></div>
+  <div class="card"><span>Amostras</span><b>{{.Stats.Count}}</b></div>
+</div>
+<p class="note">
+  Latência mínima {{ms .Stats.LatMin}}, máxima {{ms .Stats.LatMax}}.
+  {{if .NormalLatMs}}Latência normal deste destino no horário: {{printf "%.0f" .NormalLatMs}} ms (±{{printf "%.0f" .NormalStdMs}} ms).{{end}}
+  {{if .AnomalyMinutes}}Minutos fora do padrão aprendido: {{.AnomalyMinutes}}.{{end}}
+  {{if .PlannedSamples}}{{.PlannedSamples}} amostras em janelas de manutenção foram desconsideradas.{{end}}
+</p>
+</section>
+
+<section>
+<h2>Latência ao longo do tempo</h2>
+{{.Latency}}
+<p class="note">Linha: média de cada intervalo; faixa: mínimo e máximo. Linhas tracejadas marcam as anotações.</p>
+</section>
+
+<section>
+<h2>Perda de pacotes</h2>
+{{.Loss}}
+</section>
+
+{{if .Histogram}}<section>
+<h2>Distribuição das latências</h2>
+{{.Histogram}}
+</section>{{end}}
+
+<section>
+<h2>Latência por dia da semana e hora</h2>
+{{.Heatmap}}
+<p class="note">Verde: horários mais rápidos do período; vermelho: mais lentos. Borda vermelha: perda acima de 2%.</p>
+</section>
+
+{{with .SLA}}<section>
+<h2>SLA</h2>
+<p>Disponibilidade: <b>{{pct .Availability}}</b>
+{{if gt .TargetPct 0.0}} — meta {{pct .TargetPct}}: {{if .MetTarget}}<span class="met">ATINGIDA</span>{{else}}<span class="missed">NÃO ATINGIDA</span>{{end}}{{end}}</p>
+{{if .Outages}}<p>Quedas: {{.Outages}} · Tempo fora do ar: {{secs .DowntimeSec}} · MTBF: {{secs .MTBFSec}} · MTTR: {{secs .MTTRSec}}</p>{{else}}<p>Nenhuma queda no período.</p>{{end}}
+{{if .Estimated}}<p class="note">{{slaEstimated}}</p>{{end}}
+</section>{{else}}<section>
+<h2>Disponibilidade</h2>
+<p>Pacotes respondidos: <b>{{pct (slaFromLoss .Stats.LossPct)}}</b></p>
+<p class="note">As amostras individuais do período já foram resumidas pela retenção; quedas e SLA detalhado não estão disponíveis.</p>
+</section>{{end}}
+
+{{if .Causes}}<section>
+<h2>Causa provável das perdas</h2>
+<table><tr><th>Causa</th><th>Pacotes</th></tr>
+{{range $cause, $n := .Causes}}<tr><td>{{if $cause}}{{$cause}}{{else}}indeterminada{{end}}</td><td>{{$n}}</td></tr>{{end}}
+</table>
+</section>{{end}}
+
+{{if .SLA}}<section>
+<h2>Incidentes</h2>
+{{if .Incidents}}<table>
+<tr><th>Início</th><th>Fim</th><th>Duração</th><th>Pacotes perdidos</th><th></th></tr>
+{{range .Incidents}}<tr><td>{{date .Start}}</td><td>{{with .End}}{{date .}}{{end}}</td><td>{{incidentDuration .}}</td><td>{{.LostPackets}}</td><td>{{if .Planned}}manutenção{{end}}</td></tr>
+{{end}}</table>{{else}}<p>Nenhum incidente no período.</p>{{end}}
+</section>{{end}}
+
+{{if .Annotations}}<section>
+<h2>Anotações</h2>
+<table><tr><th>Quando</th><th>Tipo</th><th>Host</th><th>Texto</th></tr>
+{{range .Annotations}}<tr><td>{{date .Start}}{{with .End}} até {{date .}}{{end}}</td><td>{{kind .Kind}}</td><td>{{if .HostID}}{{.HostID}}{{else}}todos{{end}}</td><td>{{.Text}}</td></tr>
+{{end}}</table>
+</section>{{end}}
+
+<p class="note">DICA: Valores acima de 100 ms ou perdas de sinal podem causar travamentos em vídeos e jogos.</p>
+</body>
+</html>
+`))
+
+// reportStyle é a folha de estilos das páginas HTML (relatório e comparativo)
+const reportStyle = `<style>
+  body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; color: #111827; margin: 0 auto; max-width: 820px; padding: 24px; }
+  h1 { font-size: 22px; margin: 0 0 4px; }
+  h2 { font-size: 16px; margin: 28px 0 8px; border-bottom: 1px solid #e5e7eb; padding-bottom: 4px; }
+  .meta { color: #6b7280; font-size: 13px; }
+  .verdict { display: inline-block; padding: 6px 14px; border-radius: 6px; font-weight: bold; margin: 12px 0; }
+  .verdict.ok { background: #dcfce7; color: #166534; }
+  .verdict.warn { background: #fef9c3; color: #854d0e; }
+  .verdict.bad { background: #fee2e2; color: #991b1b; }
+  .cards { display: grid; grid-template-columns: repeat(3, 1fr); gap: 8px; }
+  .card { border: 1px solid #e5e7eb; border-radius: 6px; padding: 8px 12px; }
+  .card b { display: block; font-size: 20px; }
+  .card span { color: #6b7280; font-size: 12px; }
+  table { border-collapse: collapse; width: 100%; font-size: 13px; }
+  th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #e5e7eb; }
+  th { background: #f9fafb; }
+  svg { width: 100%; height: auto; }
+  .legend { display: flex; flex-wrap: wrap; gap: 12px; font-size: 13px; margin: 4px 0; }
+  .legend svg { width: 10px; height: 10px; }
+  .note { color: #6b7280; font-size: 12px; }
+  .met { color: #166534; font-weight: bold; }
+  .missed { color: #991b1b; font-weight: bold; }
+  td.ok, td.warn, td.bad { font-weight: bold; }
+  td.ok { color: #166534; } td.warn { color: #854d0e; } td.bad { color: #991b1b; }
+  section { break-inside: avoid; page-break-inside: avoid; }
+  @media print { body { padding: 0; } @page { size: A4; margin: 15mm; } }
+</style>
+`
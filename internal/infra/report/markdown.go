@@ -0,0 +1,133 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"lag-monitor/internal/domain"
+	"strings"
+	"text/template"
+)
+
+// Markdown grava o relatório em Markdown (tabelas no estilo GitHub), para colar em
+// chamados e wikis
+func Markdown(w io.Writer, rep domain.ReportData) error {
+	return markdownTemplate.Execute(w, rep)
+}
+
+// PeriodsMarkdown grava a comparação entre períodos em Markdown
+func PeriodsMarkdown(w io.Writer, cmp domain.PeriodComparison) error {
+	return periodsMarkdownTemplate.Execute(w, periodsView{PeriodComparison: cmp, Alpha: domain.SignificanceLevel})
+}
+
+// JSON grava os dados de um relatório (ReportData, PeriodComparison...), com os mesmos
+// campos da API, para consumo por scripts
+func JSON(w io.Writer, data any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// mdEscaper neutraliza a formatação Markdown e as quebras de linha dentro das tabelas
+var mdEscaper = strings.NewReplacer("\\", `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "\r", "", "\n", " ")
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(template.FuncMap(reportFuncs)).Parse(markdownSource))
+
+const markdownSource = `# Relatório de qualidade de internet
+
+- **Destino:** {{md .HostID}}{{if and .HostName (ne .HostName .HostID)}} ({{md .HostName}}){{end}}
+- **Período:** {{date .Start}} até {{date .End}}
+- **Fonte:** {{resolution .Resolution}}
+- **Gerado em:** {{date .GeneratedAt}}
+
+**Status da conexão: {{.Status}}**
+
+## Resumo
+
+| Indicador | Valor |
+|---|---|
+| Latência média | {{ms .Stats.LatAvg}} |
+| 95% do tempo abaixo de | {{ms .Stats.LatP95}} |
+| Pior 1% (P99) | {{ms .Stats.LatP99}} |
+| Latência mínima / máxima | {{ms .Stats.LatMin}} / {{ms .Stats.LatMax}} |
+| Jitter médio | {{ms .Stats.JitterAvg}} |
+| Perda de pacotes | {{pct .Stats.LossPct}} |
+| Amostras | {{.Stats.Count}} |
+{{- if .NormalLatMs}}
+| Latência normal no horário | {{printf "%.0f" .NormalLatMs}} ms (±{{printf "%.0f" .NormalStdMs}} ms) |
+{{- end}}
+{{- if .AnomalyMinutes}}
+| Minutos fora do padrão aprendido | {{.AnomalyMinutes}} |
+{{- end}}
+{{- if .PlannedSamples}}
+| Amostras em manutenção (desconsideradas) | {{.PlannedSamples}} |
+{{- end}}
+{{with .SLA}}
+## SLA
+
+Disponibilidade: **{{pct .Availability}}** (definição: {{slaDefinition .Definition}})
+{{- if gt .TargetPct 0.0}} — meta {{pct .TargetPct}}: **{{slaVerdict .MetTarget}}**{{end}}
+
+{{if .Outages}}Quedas: {{.Outages}} · Tempo fora do ar: {{secs .DowntimeSec}} · MTBF: {{secs .MTBFSec}} · MTTR: {{secs .MTTRSec}}{{else}}Nenhuma queda no período.{{end}}
+{{- if .Estimated}}
+
+_{{slaEstimated}}_
+{{- end}}
+{{else}}
+## Disponibilidade
+
+Pacotes respondidos: **{{pct (slaFromLoss .Stats.LossPct)}}**
+
+_As amostras individuais do período já foram resumidas pela retenção; quedas e SLA detalhado não estão disponíveis._
+{{end}}
+{{- if .Causes}}
+## Causa provável das perdas
+
+| Causa | Pacotes | % das perdas |
+|---|---|---|
+{{- $total := .Stats.LossCount}}
+{{- range $cause, $n := .Causes}}
+| {{if $cause}}{{md (print $cause)}}{{else}}indeterminada{{end}} | {{$n}} | {{causePct $n $total}} |
+{{- end}}
+{{end}}
+{{- if .SLA}}
+## Incidentes
+{{if .Incidents}}
+| Início | Fim | Duração | Pacotes perdidos | |
+|---|---|---|---|---|
+{{- range .Incidents}}
+| {{date .Start}} | {{with .End}}{{date .}}{{end}} | {{incidentDuration .}} | {{.LostPackets}} | {{if .Planned}}manutenção{{end}} |
+{{- end}}
+{{else}}
+Nenhum incidente no período.
+{{end}}
+{{- end}}
+{{- if .Annotations}}
+## Anotações
+
+| Quando | Tipo | Host | Texto |
+|---|---|---|---|
+{{- range .Annotations}}
+| {{date .Start}}{{with .End}} até {{date .}}{{end}} | {{kind .Kind}} | {{if .HostID}}{{md .HostID}}{{else}}todos{{end}} | {{md .Text}} |
+{{- end}}
+{{end}}
+`
+
+var periodsMarkdownTemplate = template.Must(template.New("periods").Funcs(template.FuncMap(reportFuncs)).Parse(periodsMarkdownSource))
+
+const periodsMarkdownSource = `# Comparação entre períodos
+
+- **Destino:** {{md .HostID}}{{if and .HostName (ne .HostName .HostID)}} ({{md .HostName}}){{end}}
+- **Período base:** {{date .Base.Start}} até {{date .Base.End}}
+- **Período comparado:** {{date .Current.Start}} até {{date .Current.End}}
+- **Gerado em:** {{date .GeneratedAt}}
+
+| Indicador | Período base | Período comparado | Variação | Resultado |
+|---|---|---|---|---|
+{{- range .Deltas}}
+| {{.Label}} | {{printf "%.2f" .Base}} {{.Unit}} | {{printf "%.2f" .Current}} {{.Unit}} | {{signed .Delta}} {{.Unit}}{{if .DeltaPct}} ({{signed .DeltaPct}}%){{end}} | {{if .Significant}}**{{.Verdict}}**{{else}}{{.Verdict}}{{end}} ({{pvalue .PValue}}, {{.Test}}) |
+{{- end}}
+| Status | {{.Base.Status}} | {{.Current.Status}} | | |
+| Amostras | {{.Base.Stats.Count}} | {{.Current.Stats.Count}} | | |
+
+_Significativo quando p < {{printf "%.2f" .Alpha}}. Latência, jitter e perda: Mann-Whitney sobre intervalos de {{.Bucket}}; incidentes: taxas de Poisson (teste exato)._
+`
@@ -0,0 +1,620 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"lag-monitor/internal/domain"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Página A4 em mm
+const (
+	pdfMargin = 15.0
+	pdfWidth  = 180.0 // Largura útil
+	pdfBottom = 282.0 // Limite do conteúdo (o rodapé fica abaixo)
+	pdfAxis   = 14.0  // Espaço dos rótulos do eixo Y
+)
+
+const colorInk = "#111827"
+
+// Fundo e texto do destaque do veredito (os mesmos da página HTML)
+var verdictColors = map[string][2]string{
+	"ok":   {"#dcfce7", "#166534"},
+	"warn": {"#fef9c3", "#854d0e"},
+	"bad":  {"#fee2e2", "#991b1b"},
+}
+
+// Símbolos fora da página de código Windows-1252 das fontes padrão do PDF
+var pdfSymbols = strings.NewReplacer("≥", ">=", "→", "->")
+
+// PDF grava o relatório em A4, sem ferramentas externas: capa com período e veredito,
+// gráficos, SLA, incidentes, anotações e um anexo com as amostras brutas. Sem samples,
+// o anexo lista os agregados da série do relatório.
+func PDF(w io.Writer, rep domain.ReportData, samples []domain.PingResult) error {
+	f := fpdf.New("P", "mm", "A4", "")
+	d := pdfDoc{Fpdf: f, tr: f.UnicodeTranslatorFromDescriptor("")}
+	d.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	d.SetAutoPageBreak(true, 297-pdfBottom)
+	d.SetTitle("Relatório de qualidade de internet – "+rep.HostID, true)
+	d.SetCreator("lag-monitor", false)
+	d.SetCreationDate(rep.GeneratedAt)
+	d.AliasNbPages("")
+	d.SetFooterFunc(func() {
+		d.SetY(-12)
+		d.SetFont("Helvetica", "", 7)
+		d.SetTextColor(rgb(colorText))
+		d.CellFormat(pdfWidth/2, 4, d.txt(rep.HostID+" · "+date(rep.Start)+" até "+date(rep.End)), "", 0, "L", false, 0, "")
+		d.CellFormat(pdfWidth/2, 4, d.txt(fmt.Sprintf("Página %d/{nb}", d.PageNo())), "", 0, "R", false, 0, "")
+	})
+
+	pdfCover(d, rep)
+	pdfCharts(d, rep)
+	pdfDetails(d, rep)
+	pdfAppendix(d, rep, samples)
+	return d.Output(w)
+}
+
+type pdfDoc struct {
+	*fpdf.Fpdf
+	tr func(string) string // UTF-8 → Windows-1252
+}
+
+func (d pdfDoc) txt(s string) string {
+	return d.tr(pdfSymbols.Replace(s))
+}
+
+// fit corta o texto que não cabe na largura da coluna
+func (d pdfDoc) fit(s string, w float64) string {
+	t := d.txt(s)
+	if d.GetStringWidth(t) <= w-1.5 {
+		return t
+	}
+	for len(t) > 0 && d.GetStringWidth(t+"...") > w-1.5 {
+		t = t[:len(t)-1]
+	}
+	return t + "..."
+}
+
+// ensure quebra a página quando não há h mm livres
+func (d pdfDoc) ensure(h float64) {
+	if d.GetY()+h > pdfBottom {
+		d.AddPage()
+	}
+}
+
+func (d pdfDoc) heading(title string) {
+	d.Ln(3)
+	d.SetFont("Helvetica", "B", 12)
+	d.SetTextColor(rgb(colorInk))
+	d.SetDrawColor(rgb(colorGrid))
+	d.SetLineWidth(0.2)
+	d.CellFormat(pdfWidth, 7, d.txt(title), "B", 1, "L", false, 0, "")
+	d.Ln(2)
+}
+
+func (d pdfDoc) para(text string) {
+	d.SetFont("Helvetica", "", 10)
+	d.SetTextColor(rgb(colorInk))
+	d.MultiCell(pdfWidth, 5, d.txt(text), "", "L", false)
+}
+
+func (d pdfDoc) note(text string) {
+	d.SetFont("Helvetica", "", 8)
+	d.SetTextColor(rgb(colorText))
+	d.MultiCell(pdfWidth, 4, d.txt(text), "", "L", false)
+}
+
+// table desenha uma tabela simples, repetindo o cabeçalho a cada página
+func (d pdfDoc) table(widths []float64, header []string, rows [][]string) {
+	const rowH = 5.5
+	head := func() {
+		d.SetFont("Helvetica", "B", 8)
+		d.SetFillColor(249, 250, 251)
+		d.SetTextColor(rgb(colorInk))
+		for i, h := range header {
+			d.CellFormat(widths[i], rowH, d.txt(h), "B", 0, "L", true, 0, "")
+		}
+		d.Ln(rowH)
+		d.SetFont("Helvetica", "", 8)
+	}
+	d.SetDrawColor(rgb(colorGrid))
+	d.SetLineWidth(0.2)
+	head()
+	for _, row := range rows {
+		if d.GetY()+rowH > pdfBottom {
+			d.AddPage()
+			head()
+		}
+		for i, cell := range row {
+			d.CellFormat(widths[i], rowH, d.fit(cell, widths[i]), "B", 0, "L", false, 0, "")
+		}
+		d.Ln(rowH)
+	}
+}
+
+func pdfCover(d pdfDoc, rep domain.ReportData) {
+	d.AddPage()
+	d.SetY(40)
+	d.SetFont("Helvetica", "B", 22)
+	d.SetTextColor(rgb(colorInk))
+	d.MultiCell(pdfWidth, 10, d.txt("Relatório de qualidade de internet"), "", "L", false)
+	d.Ln(6)
+
+	host := rep.HostID
+	if rep.HostName != "" && rep.HostName != rep.HostID {
+		host += " (" + rep.HostName + ")"
+	}
+	for _, f := range [][2]string{
+		{"Destino", host},
+		{"Período", date(rep.Start) + " até " + date(rep.End)},
+		{"Fonte dos dados", resolutionLabel(rep.Resolution)},
+		{"Gerado em", date(rep.GeneratedAt)},
+	} {
+		d.SetFont("Helvetica", "", 11)
+		d.SetTextColor(rgb(colorText))
+		d.CellFormat(40, 7, d.txt(f[0]), "", 0, "L", false, 0, "")
+		d.SetFont("Helvetica", "B", 11)
+		d.SetTextColor(rgb(colorInk))
+		d.CellFormat(pdfWidth-40, 7, d.fit(f[1], pdfWidth-40), "", 1, "L", false, 0, "")
+	}
+	d.Ln(8)
+
+	colors := verdictColors[statusClass(rep.Status)]
+	d.SetFillColor(rgb(colors[0]))
+	d.SetTextColor(rgb(colors[1]))
+	d.SetFont("Helvetica", "B", 16)
+	d.CellFormat(pdfWidth, 16, d.txt("Status da conexão: "+rep.Status), "", 1, "C", true, 0, "")
+	d.Ln(8)
+
+	st := rep.Stats
+	cards := [][2]string{
+		{"Latência média", ms(float64(st.LatAvg))},
+		{"95% do tempo abaixo de", ms(float64(st.LatP95))},
+		{"Pior 1% (P99)", ms(float64(st.LatP99))},
+		{"Jitter médio", ms(float64(st.JitterAvg))},
+		{"Perda de pacotes", fmt.Sprintf("%.2f%%", st.LossPct)},
+		{"Amostras", fmt.Sprintf("%d", st.Count)},
+	}
+	const cardW, cardH = pdfWidth / 3, 20.0
+	top := d.GetY()
+	d.SetDrawColor(rgb(colorGrid))
+	d.SetLineWidth(0.2)
+	for i, c := range cards {
+		x, y := pdfMargin+float64(i%3)*cardW, top+float64(i/3)*(cardH+3)
+		d.Rect(x, y, cardW-3, cardH, "D")
+		d.SetXY(x+3, y+3)
+		d.SetFont("Helvetica", "", 9)
+		d.SetTextColor(rgb(colorText))
+		d.CellFormat(cardW-9, 4, d.txt(c[0]), "", 0, "L", false, 0, "")
+		d.SetXY(x+3, y+9)
+		d.SetFont("Helvetica", "B", 15)
+		d.SetTextColor(rgb(colorInk))
+		d.CellFormat(cardW-9, 8, d.txt(c[1]), "", 0, "L", false, 0, "")
+	}
+	d.SetXY(pdfMargin, top+2*(cardH+3)+4)
+
+	notes := []string{fmt.Sprintf("Latência mínima %s, máxima %s.", ms(float64(st.LatMin)), ms(float64(st.LatMax)))}
+	if rep.NormalLatMs > 0 {
+		notes = append(notes, fmt.Sprintf("Latência normal deste destino no horário: %.0f ms (±%.0f ms).", rep.NormalLatMs, rep.NormalStdMs))
+	}
+	if rep.AnomalyMinutes > 0 {
+		notes = append(notes, fmt.Sprintf("Minutos fora do padrão aprendido: %d.", rep.AnomalyMinutes))
+	}
+	if rep.PlannedSamples > 0 {
+		notes = append(notes, fmt.Sprintf("%d amostras em janelas de manutenção foram desconsideradas.", rep.PlannedSamples))
+	}
+	d.note(strings.Join(notes, " "))
+	d.Ln(6)
+	d.para("As medições foram feitas continuamente por este computador, enviando pacotes ICMP (ping) " +
+		"ao destino e registrando o tempo de resposta de cada um. Os horários estão no fuso local. " +
+		"O anexo ao final lista as medições que embasam os números deste relatório.")
+}
+
+func pdfCharts(d pdfDoc, rep domain.ReportData) {
+	d.AddPage()
+	d.heading("Latência ao longo do tempo")
+	pdfLatency(d, rep)
+	d.note("Linha: média de cada intervalo; faixa: mínimo e máximo. Linhas tracejadas marcam as anotações.")
+
+	d.ensure(48)
+	d.heading("Perda de pacotes")
+	pdfLoss(d, rep)
+
+	if len(rep.Histogram) > 0 {
+		d.ensure(68)
+		d.heading("Distribuição das latências")
+		pdfHistogram(d, rep.Histogram)
+	}
+
+	d.ensure(70)
+	d.heading("Latência por dia da semana e hora")
+	pdfHeatmap(d, rep.Heatmap)
+	d.note("Verde: horários mais rápidos do período; vermelho: mais lentos. Borda vermelha: perda acima de 2%.")
+}
+
+// plotArea reserva a área de um gráfico a partir da posição atual; a altura inclui
+// os rótulos do eixo X
+func (d pdfDoc) plotArea(height float64, start, end time.Time, yMax float64) plot {
+	return plot{
+		left: pdfMargin + pdfAxis, top: d.GetY() + 1.5,
+		width: pdfWidth - pdfAxis, height: height - 7.5,
+		start: start, end: end, yMax: yMax,
+	}
+}
+
+func (d pdfDoc) yAxis(p plot, ticks int, label func(v float64) string) {
+	d.SetFont("Helvetica", "", 6)
+	d.SetTextColor(rgb(colorText))
+	d.SetDrawColor(rgb(colorGrid))
+	d.SetLineWidth(0.1)
+	for i := 0; i <= ticks; i++ {
+		v := p.yMax * float64(i) / float64(ticks)
+		y := p.y(v)
+		d.Line(p.left, y, p.left+p.width, y)
+		d.SetXY(pdfMargin, y-1.5)
+		d.CellFormat(pdfAxis-1.5, 3, d.txt(label(v)), "", 0, "R", false, 0, "")
+	}
+}
+
+func (d pdfDoc) timeAxis(p plot) {
+	const ticks = 6
+	d.SetFont("Helvetica", "", 6)
+	d.SetTextColor(rgb(colorText))
+	for i := 0; i <= ticks; i++ {
+		t := p.tickTime(i, ticks)
+		label := t.Format(p.timeLayout())
+		x := p.x(t) - d.GetStringWidth(label)/2
+		if i == 0 {
+			x = p.x(t)
+		} else if i == ticks {
+			x = p.x(t) - d.GetStringWidth(label)
+		}
+		d.Text(x, p.bottom()+4, label)
+	}
+}
+
+func (d pdfDoc) annotationMarks(p plot, notes []domain.Annotation) {
+	d.SetDrawColor(rgb(colorNote))
+	d.SetFillColor(rgb(colorNote))
+	d.SetLineWidth(0.2)
+	for _, a := range notes {
+		x1, x2 := p.x(a.Start), p.x(a.Until())
+		if x2-x1 >= 0.5 {
+			d.SetAlpha(0.08, "Normal")
+			d.Rect(x1, p.top, x2-x1, p.height, "F")
+			d.SetAlpha(1, "Normal")
+		}
+		d.SetDashPattern([]float64{1, 0.7}, 0)
+		d.Line(x1, p.top, x1, p.bottom())
+		d.SetDashPattern([]float64{}, 0)
+	}
+}
+
+func pdfLatency(d pdfDoc, rep domain.ReportData) {
+	p := d.plotArea(62, rep.Start, rep.End, latencyScale(rep.Series))
+	d.yAxis(p, 4, ms)
+	d.annotationMarks(p, rep.Annotations)
+
+	segments := latencySegments(rep.Series)
+	d.SetFillColor(rgb(colorBand))
+	for _, seg := range segments {
+		band := make([]fpdf.PointType, 0, 2*len(seg))
+		for _, pt := range seg {
+			band = append(band, fpdf.PointType{X: p.x(pt.Time), Y: p.y(float64(pt.LatMax))})
+		}
+		for i := len(seg) - 1; i >= 0; i-- {
+			band = append(band, fpdf.PointType{X: p.x(seg[i].Time), Y: p.y(float64(seg[i].LatMin))})
+		}
+		d.Polygon(band, "F")
+	}
+	d.SetDrawColor(rgb(colorLine))
+	d.SetLineWidth(0.3)
+	for _, seg := range segments {
+		for i := 1; i < len(seg); i++ {
+			d.Line(p.x(seg[i-1].Time), p.y(float64(seg[i-1].LatAvg)), p.x(seg[i].Time), p.y(float64(seg[i].LatAvg)))
+		}
+	}
+	d.timeAxis(p)
+	d.SetY(p.bottom() + 6)
+}
+
+func pdfLoss(d pdfDoc, rep domain.ReportData) {
+	p := d.plotArea(32, rep.Start, rep.End, 100)
+	d.yAxis(p, 2, func(v float64) string { return fmt.Sprintf("%.0f%%", v) })
+
+	width := time.Duration(rep.Series.BucketSec) * time.Second
+	d.SetFillColor(rgb(colorLoss))
+	for _, pt := range rep.Series.Points {
+		if pt.LossRatio <= 0 {
+			continue
+		}
+		x1, x2 := p.x(pt.Time), p.x(pt.Time.Add(width))
+		y := p.y(pt.LossRatio * 100)
+		d.Rect(x1, y, math.Max(x2-x1, 0.3), p.bottom()-y, "F")
+	}
+	d.timeAxis(p)
+	d.SetY(p.bottom() + 6)
+}
+
+func pdfHistogram(d pdfDoc, bins []domain.HistogramBin) {
+	l := layoutHistogram(bins)
+	p := d.plotArea(52, time.Time{}, time.Time{}, l.yMax)
+	d.yAxis(p, 4, func(v float64) string { return fmt.Sprintf("%.0f", v) })
+	slotW := p.width / float64(l.slots)
+
+	d.SetFillColor(rgb(colorHistBar))
+	for _, bin := range bins {
+		y := p.y(float64(bin.Count))
+		d.Rect(p.left+float64(bin.From/l.step)*slotW+0.15, y, math.Max(slotW-0.3, 0.15), p.bottom()-y, "F")
+	}
+
+	d.SetFont("Helvetica", "", 6)
+	d.SetTextColor(rgb(colorText))
+	every := max(1, l.slots/8)
+	for i := 0; i < l.slots; i += every {
+		label := ms(float64(int64(i) * l.step))
+		d.Text(p.left+float64(i)*slotW+slotW/2-d.GetStringWidth(label)/2, p.bottom()+4, label)
+	}
+	if l.overflow {
+		label := d.txt("≥ " + ms(float64(bins[len(bins)-1].From)))
+		d.Text(p.left+p.width-d.GetStringWidth(label), p.bottom()+4, label)
+	}
+	d.SetY(p.bottom() + 6)
+}
+
+func pdfHeatmap(d pdfDoc, cells []domain.HeatCell) {
+	byKey, lo, hi := heatGrid(cells)
+
+	const rowH = 6.0
+	cell := (pdfWidth - pdfAxis) / 24
+	top := d.GetY() + 1
+	d.SetFont("Helvetica", "", 6)
+	for row, wd := range heatWeekdays {
+		y := top + float64(row)*rowH
+		label := d.txt(wd.label)
+		d.SetTextColor(rgb(colorText))
+		d.Text(pdfMargin+pdfAxis-2-d.GetStringWidth(label), y+rowH/2+1, label)
+		for h := 0; h < 24; h++ {
+			c := byKey[[2]int{int(wd.day), h}]
+			x := pdfMargin + pdfAxis + float64(h)*cell
+			switch {
+			case c.Count > 0 && c.LatAvg == 0: // Nenhuma resposta no horário
+				d.SetFillColor(rgb(colorLoss))
+			case c.Count > 0:
+				d.SetFillColor(hslRGB(heatHue(c.LatAvg, lo, hi), 0.7, 0.55))
+			default:
+				d.SetFillColor(rgb(colorEmpty))
+			}
+			style := "F"
+			if c.LossPct > 2 {
+				d.SetDrawColor(rgb(colorLoss))
+				d.SetLineWidth(0.35)
+				style = "FD"
+			}
+			d.Rect(x+0.2, y+0.2, cell-0.4, rowH-0.4, style)
+		}
+	}
+	d.SetTextColor(rgb(colorText))
+	for h := 0; h < 24; h += 2 {
+		label := fmt.Sprintf("%02dh", h)
+		d.Text(pdfMargin+pdfAxis+float64(h)*cell+cell/2-d.GetStringWidth(label)/2, top+7*rowH+3.5, label)
+	}
+	d.SetY(top + 7*rowH + 6)
+}
+
+func pdfDetails(d pdfDoc, rep domain.ReportData) {
+	d.AddPage()
+	if sla := rep.SLA; sla != nil {
+		d.heading("SLA")
+		d.SetFont("Helvetica", "", 10)
+		d.SetTextColor(rgb(colorInk))
+		d.Write(5, d.txt(fmt.Sprintf("Disponibilidade: %.2f%%", sla.Availability)))
+		if sla.TargetPct > 0 {
+			d.Write(5, d.txt(fmt.Sprintf(" — meta %.2f%%: ", sla.TargetPct)))
+			d.SetFont("Helvetica", "B", 10)
+			if sla.MetTarget {
+				d.SetTextColor(rgb(verdictColors["ok"][1]))
+			} else {
+				d.SetTextColor(rgb(verdictColors["bad"][1]))
+			}
+			d.Write(5, d.txt(slaVerdict(sla.MetTarget)))
+		}
+		d.Ln(6)
+		if sla.Outages > 0 {
+			d.para(fmt.Sprintf("Quedas: %d · Tempo fora do ar: %s · MTBF: %s · MTTR: %s",
+				sla.Outages, secs(sla.DowntimeSec), secs(sla.MTBFSec), secs(sla.MTTRSec)))
+		} else {
+			d.para("Nenhuma queda no período.")
+		}
+		if sla.Definition != "" {
+			d.note("Definição de disponibilidade: " + slaDefinition(sla.Definition))
+		}
+		if sla.Estimated {
+			d.note(slaEstimatedNote)
+		}
+	} else {
+		d.heading("Disponibilidade")
+		d.para(fmt.Sprintf("Pacotes respondidos: %.2f%%", 100-rep.Stats.LossPct))
+		d.note("As amostras individuais do período já foram resumidas pela retenção; quedas e SLA detalhado não estão disponíveis.")
+	}
+
+	if len(rep.Causes) > 0 {
+		d.ensure(30)
+		d.heading("Causa provável das perdas")
+		causes := make([]domain.RootCause, 0, len(rep.Causes))
+		for c := range rep.Causes {
+			causes = append(causes, c)
+		}
+		sort.Slice(causes, func(i, j int) bool { return rep.Causes[causes[i]] > rep.Causes[causes[j]] })
+		rows := make([][]string, 0, len(causes))
+		for _, c := range causes {
+			label := string(c)
+			if c == domain.CauseUnknown {
+				label = "indeterminada"
+			}
+			rows = append(rows, []string{label, fmt.Sprintf("%d", rep.Causes[c])})
+		}
+		d.table([]float64{140, 40}, []string{"Causa", "Pacotes"}, rows)
+	}
+
+	if rep.SLA != nil {
+		d.ensure(30)
+		d.heading("Incidentes")
+		if len(rep.Incidents) == 0 {
+			d.para("Nenhum incidente no período.")
+		} else {
+			rows := make([][]string, 0, len(rep.Incidents))
+			for _, inc := range rep.Incidents {
+				end, planned := "", ""
+				if inc.End != nil {
+					end = date(*inc.End)
+				}
+				if inc.Planned {
+					planned = "manutenção"
+				}
+				rows = append(rows, []string{date(inc.Start), end, incidentDuration(inc), fmt.Sprintf("%d", inc.LostPackets), planned})
+			}
+			d.table([]float64{38, 38, 34, 35, 35}, []string{"Início", "Fim", "Duração", "Pacotes perdidos", ""}, rows)
+		}
+	}
+
+	if len(rep.Annotations) > 0 {
+		d.ensure(30)
+		d.heading("Anotações")
+		rows := make([][]string, 0, len(rep.Annotations))
+		for _, a := range rep.Annotations {
+			when := date(a.Start)
+			if a.End != nil {
+				when += " até " + date(*a.End)
+			}
+			host := a.HostID
+			if host == "" {
+				host = "todos"
+			}
+			rows = append(rows, []string{when, kindLabel(a.Kind), host, a.Text})
+		}
+		d.table([]float64{52, 22, 30, 76}, []string{"Quando", "Tipo", "Host", "Texto"}, rows)
+	}
+
+	d.Ln(4)
+	d.note("DICA: Valores acima de 100 ms ou perdas de sinal podem causar travamentos em vídeos e jogos.")
+}
+
+func pdfAppendix(d pdfDoc, rep domain.ReportData, samples []domain.PingResult) {
+	d.AddPage()
+	if samples != nil {
+		d.heading("Anexo – amostras brutas")
+		d.note(fmt.Sprintf("%d medições de %s até %s, uma por linha, em ordem. Perdas em vermelho. "+
+			"A mesma lista pode ser exportada em CSV pelo aplicativo.", len(samples), date(rep.Start), date(rep.End)))
+		d.Ln(2)
+		d.columns([]float64{25, 12, 12, 11}, []string{"Horário", "Lat. (ms)", "Jitter (ms)", ""}, len(samples),
+			func(i int) ([]string, bool) {
+				s := samples[i]
+				when := s.Timestamp.Format("02/01/06 15:04:05")
+				if s.Loss {
+					return []string{when, "-", "-", "perda"}, true
+				}
+				return []string{when, fmt.Sprintf("%.1f", float64(s.Latency)/1000), fmt.Sprintf("%.1f", float64(s.Jitter)/1000), ""}, false
+			})
+		return
+	}
+
+	points := make([]domain.SeriesPoint, 0, len(rep.Series.Points))
+	for _, pt := range rep.Series.Points {
+		if pt.Count > 0 {
+			points = append(points, pt)
+		}
+	}
+	bucket := time.Duration(rep.Series.BucketSec) * time.Second
+	d.heading("Anexo – medições agregadas")
+	if rep.Resolution != domain.ResolutionRaw {
+		d.note(fmt.Sprintf("As medições individuais deste período já foram resumidas pela retenção. "+
+			"Cada linha resume um intervalo de %s.", bucket))
+	} else {
+		d.note(fmt.Sprintf("O período tem medições demais para listar uma a uma; cada linha resume um intervalo de %s. "+
+			"A lista completa pode ser exportada em CSV pelo aplicativo.", bucket))
+	}
+	d.Ln(2)
+	d.columns([]float64{21, 13, 13, 13}, []string{"Início", "Média (ms)", "Máx. (ms)", "Perda"}, len(points),
+		func(i int) ([]string, bool) {
+			pt := points[i]
+			row := []string{pt.Time.Format("02/01/06 15:04"), "-", "-", fmt.Sprintf("%.1f%%", pt.LossRatio*100)}
+			if answered(pt) {
+				row[1], row[2] = fmt.Sprintf("%.1f", float64(pt.LatAvg)/1000), fmt.Sprintf("%.1f", float64(pt.LatMax)/1000)
+			}
+			return row, pt.LossRatio > 0
+		})
+}
+
+// columns distribui n linhas em três colunas por página, de cima para baixo. Linhas
+// marcadas saem em vermelho.
+func (d pdfDoc) columns(widths []float64, header []string, n int, row func(i int) ([]string, bool)) {
+	const rowH, cols = 3.2, 3
+	colW := pdfWidth / cols
+	d.SetDrawColor(rgb(colorGrid))
+	d.SetLineWidth(0.2)
+	for i := 0; i < n; {
+		d.ensure(2 * rowH)
+		top := d.GetY()
+		perCol := int((pdfBottom - top - rowH) / rowH)
+		for c := 0; c < cols && i < n; c++ {
+			x := pdfMargin + float64(c)*colW
+			d.SetXY(x, top)
+			d.SetFont("Helvetica", "B", 6)
+			d.SetTextColor(rgb(colorInk))
+			for k, h := range header {
+				d.CellFormat(widths[k], rowH, d.txt(h), "B", 0, "L", false, 0, "")
+			}
+			d.SetFont("Helvetica", "", 6)
+			for r := 0; r < perCol && i < n; r, i = r+1, i+1 {
+				cells, alert := row(i)
+				if alert {
+					d.SetTextColor(rgb(colorLoss))
+				} else {
+					d.SetTextColor(rgb(colorInk))
+				}
+				d.SetXY(x, top+float64(r+1)*rowH)
+				for k, cell := range cells {
+					d.CellFormat(widths[k], rowH, d.txt(cell), "", 0, "L", false, 0, "")
+				}
+			}
+		}
+		d.SetY(pdfBottom)
+	}
+}
+
+// rgb converte uma cor "#rrggbb"
+func rgb(hex string) (r, g, b int) {
+	fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b)
+	return r, g, b
+}
+
+// hslRGB converte uma cor HSL (matiz em graus; saturação e luminosidade de 0 a 1)
+func hslRGB(h, s, l float64) (int, int, int) {
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := math.Mod(h, 360) / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g = c, x
+	case hp < 2:
+		r, g = x, c
+	case hp < 3:
+		g, b = c, x
+	case hp < 4:
+		g, b = x, c
+	case hp < 5:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := l - c/2
+	conv := func(v float64) int { return int(math.Round((v + m) * 255)) }
+	return conv(r), conv(g), conv(b)
+}
@@ -0,0 +1,87 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"lag-monitor/internal/domain"
+)
+
+// PeriodsHTML grava a comparação entre dois períodos do mesmo host: indicadores de cada
+// período, a variação com o teste de significância e as latências sobrepostas
+func PeriodsHTML(w io.Writer, cmp domain.PeriodComparison) error {
+	// A série base é deslocada para o início do período comparado, para sobrepor as duas
+	offset := cmp.Current.Start.Sub(cmp.Base.Start)
+	base := cmp.Base.Series
+	base.Points = make([]domain.SeriesPoint, len(cmp.Base.Series.Points))
+	for i, pt := range cmp.Base.Series.Points {
+		pt.Time = pt.Time.Add(offset)
+		base.Points[i] = pt
+	}
+	span := max(cmp.Base.End.Sub(cmp.Base.Start), cmp.Current.End.Sub(cmp.Current.Start))
+
+	return periodsTemplate.Execute(w, periodsView{
+		PeriodComparison: cmp,
+		Alpha:            domain.SignificanceLevel,
+		Legend: Legend([]string{
+			"Base: " + date(cmp.Base.Start) + " até " + date(cmp.Base.End),
+			"Comparado: " + date(cmp.Current.Start) + " até " + date(cmp.Current.End),
+		}),
+		Latency: CompareLatencyChart([]domain.HostSeries{base, cmp.Current.Series},
+			cmp.Current.Start, cmp.Current.Start.Add(span)),
+	})
+}
+
+type periodsView struct {
+	domain.PeriodComparison
+	Alpha           float64
+	Legend, Latency template.HTML
+}
+
+// AlphaPct é o nível de significância em porcentagem
+func (v periodsView) AlphaPct() float64 { return v.Alpha * 100 }
+
+// Bucket é o intervalo das amostras usadas nos testes de latência e jitter
+func (v periodsView) Bucket() string {
+	return secs(float64(v.BucketSec))
+}
+
+var periodsTemplate = template.Must(template.New("periods").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Comparação entre períodos — {{.HostID}}</title>
+` + reportStyle + `</head>
+<body>
+<h1>Comparação entre períodos</h1>
+<div class="meta">
+  Destino: <b>{{.HostID}}</b>{{if and .HostName (ne .HostName .HostID)}} ({{.HostName}}){{end}}<br>
+  Período base: {{date .Base.Start}} até {{date .Base.End}}<br>
+  Período comparado: {{date .Current.Start}} até {{date .Current.End}}<br>
+  Gerado em {{date .GeneratedAt}}
+</div>
+
+<section>
+<h2>Indicadores</h2>
+<table>
+<tr><th>Indicador</th><th>Período base</th><th>Período comparado</th><th>Variação</th><th>Resultado</th></tr>
+{{range .Deltas}}<tr><td>{{.Label}}</td><td>{{printf "%.2f" .Base}} {{.Unit}}</td><td>{{printf "%.2f" .Current}} {{.Unit}}</td><td>{{signed .Delta}} {{.Unit}}{{if .DeltaPct}} ({{signed .DeltaPct}}%){{end}}</td><td class="{{verdictClass .}}">{{.Verdict}} <small>({{pvalue .PValue}}, {{.Test}})</small></td></tr>
+{{end}}<tr><td>Status</td><td class="{{statusClass .Base.Status}}">{{.Base.Status}}</td><td class="{{statusClass .Current.Status}}">{{.Current.Status}}</td><td></td><td></td></tr>
+<tr><td>Amostras</td><td>{{.Base.Stats.Count}}</td><td>{{.Current.Stats.Count}}</td><td></td><td></td></tr>
+</table>
+<p class="note">Uma variação é significativa quando o p-valor fica abaixo de {{printf "%.2f" .Alpha}}: a chance de uma
+diferença desse tamanho aparecer só por acaso é menor que {{printf "%.0f%%" .AlphaPct}}.
+Latência, jitter e perda são comparados pelo teste de Mann-Whitney sobre o valor de cada intervalo de
+{{.Bucket}}; os incidentes (fora de manutenção), pelo teste exato de taxas de Poisson.{{if not (and .Base.SLA .Current.SLA)}}
+Incidentes exigem amostras individuais ou agregados por minuto nos dois períodos.{{end}}</p>
+</section>
+
+<section>
+<h2>Latência média</h2>
+{{.Legend}}
+{{.Latency}}
+<p class="note">O período base foi deslocado para começar junto com o período comparado.</p>
+</section>
+</body>
+</html>
+`))
@@ -0,0 +1,303 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"lag-monitor/internal/domain"
+	"math"
+	"strings"
+	"time"
+)
+
+// Dimensões dos gráficos (unidades do viewBox; o SVG escala com a página)
+const (
+	chartWidth  = 760
+	chartLeft   = 52 // Espaço dos rótulos do eixo Y
+	chartRight  = 12
+	chartTop    = 10
+	chartBottom = 24 // Espaço dos rótulos do eixo X
+)
+
+// Cores usadas nos gráficos e na página
+const (
+	colorLine    = "#2563eb"
+	colorBand    = "#bfdbfe"
+	colorLoss    = "#dc2626"
+	colorGrid    = "#e5e7eb"
+	colorText    = "#6b7280"
+	colorNote    = "#7c3aed"
+	colorEmpty   = "#f3f4f6"
+	colorHistBar = "#0891b2"
+)
+
+// svgPlot cria a área útil de um gráfico SVG com a altura total informada
+func svgPlot(height float64, start, end time.Time, yMax float64) plot {
+	return plot{
+		left: chartLeft, top: chartTop,
+		width: chartWidth - chartLeft - chartRight, height: height - chartTop - chartBottom,
+		start: start, end: end, yMax: yMax,
+	}
+}
+
+func svgOpen(b *strings.Builder, height float64, label string) {
+	fmt.Fprintf(b, `<svg viewBox="0 0 %d %.0f" role="img" aria-label="%s" xmlns="http://www.w3.org/2000/svg" font-family="sans-serif" font-size="10">`,
+		chartWidth, height, html.EscapeString(label))
+}
+
+// yAxis desenha linhas de grade com os rótulos do eixo Y
+func yAxis(b *strings.Builder, p plot, ticks int, label func(v float64) string) {
+	for i := 0; i <= ticks; i++ {
+		v := p.yMax * float64(i) / float64(ticks)
+		y := p.y(v)
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`, p.left, y, p.left+p.width, y, colorGrid)
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="end" fill="%s">%s</text>`, p.left-4, y+3, colorText, html.EscapeString(label(v)))
+	}
+}
+
+// timeAxis desenha rótulos de horário ao longo do período
+func timeAxis(b *strings.Builder, p plot) {
+	const ticks = 6
+	for i := 0; i <= ticks; i++ {
+		t := p.tickTime(i, ticks)
+		anchor := "middle"
+		if i == 0 {
+			anchor = "start"
+		} else if i == ticks {
+			anchor = "end"
+		}
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="%s" fill="%s">%s</text>`, p.x(t), p.bottom()+14, anchor, colorText, t.Format(p.timeLayout()))
+	}
+}
+
+// annotationMarks marca as anotações como linhas (instantes) ou faixas (intervalos)
+func annotationMarks(b *strings.Builder, p plot, notes []domain.Annotation) {
+	for _, a := range notes {
+		x1, x2 := p.x(a.Start), p.x(a.Until())
+		title := html.EscapeString(a.Start.Format("02/01 15:04") + " " + a.Text)
+		if x2-x1 >= 1 {
+			fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" fill-opacity="0.08"><title>%s</title></rect>`,
+				x1, p.top, x2-x1, p.height, colorNote, title)
+		}
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-dasharray="3 2"><title>%s</title></line>`,
+			x1, p.top, x1, p.bottom(), colorNote, title)
+	}
+}
+
+func ms(us float64) string {
+	return fmt.Sprintf("%.0f ms", us/1000)
+}
+
+// LatencyChart desenha a latência média ao longo do período, com a faixa mínimo-máximo
+// de cada intervalo. Intervalos sem resposta interrompem a linha.
+func LatencyChart(series domain.HostSeries, start, end time.Time, notes []domain.Annotation) template.HTML {
+	p := svgPlot(220, start, end, latencyScale(series))
+
+	var b strings.Builder
+	svgOpen(&b, 220, "Latência ao longo do tempo")
+	yAxis(&b, p, 4, ms)
+	annotationMarks(&b, p, notes)
+
+	var line strings.Builder
+	for _, seg := range latencySegments(series) {
+		band := make([]string, 0, 2*len(seg))
+		for _, pt := range seg {
+			band = append(band, fmt.Sprintf("%.1f,%.1f", p.x(pt.Time), p.y(float64(pt.LatMax))))
+		}
+		for i := len(seg) - 1; i >= 0; i-- {
+			band = append(band, fmt.Sprintf("%.1f,%.1f", p.x(seg[i].Time), p.y(float64(seg[i].LatMin))))
+		}
+		fmt.Fprintf(&b, `<polygon points="%s" fill="%s"/>`, strings.Join(band, " "), colorBand)
+
+		for i, pt := range seg {
+			cmd := "L"
+			if i == 0 {
+				cmd = "M"
+			}
+			fmt.Fprintf(&line, "%s%.1f %.1f ", cmd, p.x(pt.Time), p.y(float64(pt.LatAvg)))
+		}
+	}
+	if line.Len() > 0 {
+		fmt.Fprintf(&b, `<path d="%s" fill="none" stroke="%s" stroke-width="1.5"/>`, strings.TrimSpace(line.String()), colorLine)
+	}
+	timeAxis(&b, p)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// LossChart desenha a perda de cada intervalo como barras (0 a 100%)
+func LossChart(series domain.HostSeries, start, end time.Time) template.HTML {
+	p := svgPlot(110, start, end, 100)
+	width := time.Duration(series.BucketSec) * time.Second
+
+	var b strings.Builder
+	svgOpen(&b, 110, "Perda de pacotes ao longo do tempo")
+	yAxis(&b, p, 2, func(v float64) string { return fmt.Sprintf("%.0f%%", v) })
+	for _, pt := range series.Points {
+		if pt.LossRatio <= 0 {
+			continue
+		}
+		x1, x2 := p.x(pt.Time), p.x(pt.Time.Add(width))
+		y := p.y(pt.LossRatio * 100)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %.1f%%</title></rect>`,
+			x1, y, math.Max(x2-x1, 1), p.bottom()-y, colorLoss, pt.Time.Format("02/01 15:04"), pt.LossRatio*100)
+	}
+	timeAxis(&b, p)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// HistogramChart desenha a distribuição das latências. A última faixa pode agrupar
+// todas as latências acima do limite do gráfico.
+func HistogramChart(bins []domain.HistogramBin) template.HTML {
+	if len(bins) == 0 {
+		return ""
+	}
+	l := layoutHistogram(bins)
+	p := svgPlot(200, time.Time{}, time.Time{}, l.yMax)
+	slotW := p.width / float64(l.slots)
+	last := bins[len(bins)-1]
+
+	var b strings.Builder
+	svgOpen(&b, 200, "Distribuição das latências")
+	yAxis(&b, p, 4, func(v float64) string { return fmt.Sprintf("%.0f", v) })
+	for _, bin := range bins {
+		i := float64(bin.From / l.step)
+		label := fmt.Sprintf("%s – %s", ms(float64(bin.From)), ms(float64(bin.To)))
+		if l.overflow && bin == last {
+			label = "≥ " + ms(float64(bin.From))
+		}
+		y := p.y(float64(bin.Count))
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %d</title></rect>`,
+			p.left+i*slotW+0.5, y, math.Max(slotW-1, 0.5), p.bottom()-y, colorHistBar, html.EscapeString(label), bin.Count)
+	}
+
+	// Rótulos a cada ~1/8 do eixo
+	every := max(1, l.slots/8)
+	for i := 0; i < l.slots; i += every {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="%s">%s</text>`,
+			p.left+float64(i)*slotW+slotW/2, p.bottom()+14, colorText, ms(float64(int64(i)*l.step)))
+	}
+	if l.overflow {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="end" fill="%s">≥ %s</text>`,
+			p.left+p.width, p.bottom()+14, colorText, ms(float64(last.From)))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// Heatmap desenha a latência média por dia da semana e hora. A cor vai do verde
+// (menor média do período) ao vermelho (maior); horários com perda acima de 2% ganham
+// borda vermelha.
+func Heatmap(cells []domain.HeatCell) template.HTML {
+	byKey, lo, hi := heatGrid(cells)
+
+	const cell, rowH = 28.0, 20.0
+	height := chartTop + 7*rowH + chartBottom
+	var b strings.Builder
+	svgOpen(&b, height, "Latência média por dia da semana e hora")
+	for row, wd := range heatWeekdays {
+		y := chartTop + float64(row)*rowH
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" fill="%s">%s</text>`, chartLeft-6, y+rowH/2+3, colorText, wd.label)
+		for h := 0; h < 24; h++ {
+			c := byKey[[2]int{int(wd.day), h}]
+			x := chartLeft + float64(h)*cell
+			fill, title := colorEmpty, fmt.Sprintf("%s %02dh: sem dados", wd.label, h)
+			switch {
+			case c.Count > 0 && c.LatAvg == 0: // Nenhuma resposta no horário
+				fill = colorLoss
+				title = fmt.Sprintf("%s %02dh: sem respostas", wd.label, h)
+			case c.Count > 0:
+				fill = fmt.Sprintf("hsl(%.0f, 70%%, 55%%)", heatHue(c.LatAvg, lo, hi))
+				title = fmt.Sprintf("%s %02dh: %s, perda %.1f%%", wd.label, h, ms(float64(c.LatAvg)), c.LossPct)
+			}
+			stroke := "#ffffff"
+			if c.LossPct > 2 {
+				stroke = colorLoss
+			}
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" stroke="%s"><title>%s</title></rect>`,
+				x, y, cell-1, rowH-1, fill, stroke, html.EscapeString(title))
+		}
+	}
+	for h := 0; h < 24; h += 2 {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="%s">%02dh</text>`,
+			chartLeft+float64(h)*cell+cell/2, chartTop+7*rowH+14, colorText, h)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// Cores das séries na comparação entre hosts (repetem a partir do nono host)
+var seriesColors = []string{"#2563eb", "#dc2626", "#16a34a", "#d97706", "#7c3aed", "#0891b2", "#db2777", "#4b5563"}
+
+func seriesColor(i int) string {
+	return seriesColors[i%len(seriesColors)]
+}
+
+// seriesPath desenha cada trecho contínuo como uma linha
+func seriesPath(b *strings.Builder, p plot, segs [][]domain.SeriesPoint, value func(domain.SeriesPoint) float64, color string) {
+	var d strings.Builder
+	for _, seg := range segs {
+		for i, pt := range seg {
+			cmd := "L"
+			if i == 0 {
+				cmd = "M"
+			}
+			fmt.Fprintf(&d, "%s%.1f %.1f ", cmd, p.x(pt.Time), p.y(value(pt)))
+		}
+	}
+	if d.Len() > 0 {
+		fmt.Fprintf(b, `<path d="%s" fill="none" stroke="%s" stroke-width="1.5" stroke-opacity="0.85"/>`, strings.TrimSpace(d.String()), color)
+	}
+}
+
+// CompareLatencyChart sobrepõe a latência média dos hosts, uma cor por host
+func CompareLatencyChart(series []domain.HostSeries, start, end time.Time) template.HTML {
+	var avgs []float64
+	for _, s := range series {
+		for _, pt := range s.Points {
+			if answered(pt) {
+				avgs = append(avgs, float64(pt.LatAvg))
+			}
+		}
+	}
+	p := svgPlot(240, start, end, peakScale(avgs))
+
+	var b strings.Builder
+	svgOpen(&b, 240, "Latência média dos hosts ao longo do tempo")
+	yAxis(&b, p, 4, ms)
+	for i, s := range series {
+		seriesPath(&b, p, latencySegments(s), func(pt domain.SeriesPoint) float64 { return float64(pt.LatAvg) }, seriesColor(i))
+	}
+	timeAxis(&b, p)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// CompareLossChart sobrepõe a perda de cada host (0 a 100%), uma cor por host
+func CompareLossChart(series []domain.HostSeries, start, end time.Time) template.HTML {
+	p := svgPlot(140, start, end, 100)
+
+	var b strings.Builder
+	svgOpen(&b, 140, "Perda de pacotes dos hosts ao longo do tempo")
+	yAxis(&b, p, 2, func(v float64) string { return fmt.Sprintf("%.0f%%", v) })
+	measured := func(pt domain.SeriesPoint) bool { return pt.Count > 0 }
+	for i, s := range series {
+		seriesPath(&b, p, segments(s, measured), func(pt domain.SeriesPoint) float64 { return pt.LossRatio * 100 }, seriesColor(i))
+	}
+	timeAxis(&b, p)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// Legend identifica a cor de cada host nos gráficos sobrepostos
+func Legend(names []string) template.HTML {
+	var b strings.Builder
+	b.WriteString(`<div class="legend">`)
+	for i, name := range names {
+		fmt.Fprintf(&b, `<span><svg width="10" height="10" xmlns="http://www.w3.org/2000/svg"><rect width="10" height="10" fill="%s"/></svg> %s</span>`,
+			seriesColor(i), html.EscapeString(name))
+	}
+	b.WriteString(`</div>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,138 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"lag-monitor/internal/domain"
+	"strings"
+)
+
+const textRule = "------------------------------------------\n"
+
+// Text grava o resumo amigável em texto puro (RESUMO-*.txt)
+func Text(w io.Writer, rep domain.ReportData) error {
+	st := rep.Stats
+	var b strings.Builder
+	b.WriteString("=== RELATÓRIO DE QUALIDADE DE INTERNET ===\n")
+	fmt.Fprintf(&b, "Destino: %s\n", rep.HostID)
+	fmt.Fprintf(&b, "Período: %s até %s\n", rep.Start.Format("02/01 15:04"), rep.End.Format("02/01 15:04"))
+	if rep.Resolution != domain.ResolutionRaw {
+		fmt.Fprintf(&b, "Resolução: agregados de %s\n", rep.Resolution.Duration())
+	}
+	b.WriteString(textRule)
+	fmt.Fprintf(&b, "Média de Atraso (Latência): %dms\n", st.LatAvg/1000)
+	if rep.Resolution == domain.ResolutionRaw {
+		fmt.Fprintf(&b, "Atraso em 95%% do tempo abaixo de: %dms (pior 1%%: %dms)\n", st.LatP95/1000, st.LatP99/1000)
+	}
+	if rep.NormalLatMs > 0 {
+		fmt.Fprintf(&b, "Latência Normal deste Destino: %.0fms (±%.0fms)\n", rep.NormalLatMs, rep.NormalStdMs)
+	}
+	fmt.Fprintf(&b, "Status da Conexão: %s\n", rep.Status)
+	fmt.Fprintf(&b, "Perda de Sinal: %.1f%%\n", st.LossPct)
+
+	if len(rep.Causes) > 0 && st.LossCount > 0 {
+		b.WriteString("Causa provável das perdas:\n")
+		for _, c := range []domain.RootCause{domain.CauseLAN, domain.CauseGateway, domain.CauseUpstream} {
+			if n := rep.Causes[c]; n > 0 {
+				fmt.Fprintf(&b, "  - %s: %d (%.1f%%)\n", c, n, float64(n)/float64(st.LossCount)*100)
+			}
+		}
+	}
+	if rep.AnomalyMinutes > 0 {
+		fmt.Fprintf(&b, "Minutos fora do padrão aprendido: %d\n", rep.AnomalyMinutes)
+	}
+	if rep.PlannedSamples > 0 {
+		fmt.Fprintf(&b, "Janelas de manutenção: %d amostras desconsideradas\n", rep.PlannedSamples)
+	}
+	b.WriteString(textRule)
+
+	if sla := rep.SLA; sla != nil {
+		fmt.Fprintf(&b, "SLA (definição: %s)\n", slaDefinition(sla.Definition))
+		if sla.Estimated {
+			b.WriteString(slaEstimatedNote + "\n")
+		}
+		fmt.Fprintf(&b, "Disponibilidade: %.2f%%", sla.Availability)
+		if sla.TargetPct > 0 {
+			fmt.Fprintf(&b, " (meta %.2f%%: %s)", sla.TargetPct, slaVerdict(sla.MetTarget))
+		}
+		b.WriteString("\n")
+		if sla.Outages == 0 {
+			b.WriteString("Quedas: nenhuma\n")
+		} else {
+			fmt.Fprintf(&b, "Quedas: %d | Tempo fora do ar: %s\n", sla.Outages, secs(sla.DowntimeSec))
+			fmt.Fprintf(&b, "MTBF: %s | MTTR: %s\n", secs(sla.MTBFSec), secs(sla.MTTRSec))
+		}
+	} else {
+		fmt.Fprintf(&b, "Disponibilidade (pacotes respondidos): %.2f%%\n", 100-st.LossPct)
+	}
+	b.WriteString(textRule)
+
+	if len(rep.Annotations) > 0 {
+		b.WriteString("Anotações:\n")
+		for _, a := range rep.Annotations {
+			when := a.Start.Format("02/01 15:04")
+			if a.End != nil {
+				when += " até " + a.End.Format("02/01 15:04")
+			}
+			host := ""
+			if a.HostID != "" {
+				host = " (" + a.HostID + ")"
+			}
+			fmt.Fprintf(&b, "  - %s [%s]%s %s\n", when, kindLabel(a.Kind), host, a.Text)
+		}
+		b.WriteString(textRule)
+	}
+	b.WriteString("DICA: Valores acima de 100ms ou perdas de sinal podem causar travamentos em vídeos e jogos.\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// slaEstimatedNote explica o SLA calculado em parte pelos agregados por minuto
+const slaEstimatedNote = "Parte do período já foi resumida pela retenção: ali, quedas são os minutos em que a maior parte dos pacotes se perdeu."
+
+func slaDefinition(def string) string {
+	switch def {
+	case domain.SLALoss:
+		return "perda de pacotes"
+	case domain.SLAIncident:
+		return "quedas"
+	case domain.SLALatency:
+		return "perda e latência"
+	}
+	return def
+}
+
+func slaVerdict(met bool) string {
+	if met {
+		return "ATINGIDA"
+	}
+	return "NÃO ATINGIDA"
+}
+
+// RollupCSV grava os dados técnicos dos relatórios de períodos longos: uma linha por
+// intervalo agregado em vez de uma por ping. Flush deve ser chamado ao final.
+type RollupCSV struct {
+	w      *bufio.Writer
+	header bool
+}
+
+func NewRollupCSV(w io.Writer) *RollupCSV {
+	return &RollupCSV{w: bufio.NewWriter(w)}
+}
+
+func (c *RollupCSV) Write(r domain.Rollup) error {
+	if !c.header {
+		c.header = true
+		c.w.WriteString("BUCKET;COUNT;LOSS_COUNT;MIN_MS;AVG_MS;MAX_MS;P95_MS;P99_MS;JITTER_MS\n")
+	}
+	_, err := fmt.Fprintf(c.w, "%s;%d;%d;%d;%d;%d;%d;%d;%d\n",
+		r.Bucket.Format("2006-01-02 15:04:05"), r.Count, r.LossCount,
+		r.LatMin/1000, r.LatAvg/1000, r.LatMax/1000, r.LatP95/1000, r.LatP99/1000, r.JitterAvg/1000)
+	return err
+}
+
+func (c *RollupCSV) Flush() error {
+	return c.w.Flush()
+}
@@ -0,0 +1,74 @@
+package segment
+
+import (
+	"encoding/json"
+	"lag-monitor/internal/domain"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// As anotações são poucas: ficam em um único JSON na raiz do diretório, reescrito
+// por inteiro (via arquivo temporário) a cada alteração
+const annotationsFile = "annotations.json"
+
+func (s *Store) loadAnnotations() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, annotationsFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.annotations)
+}
+
+// saveAnnotations grava a lista completa. Requer s.mu.
+func (s *Store) saveAnnotations(list []domain.Annotation) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, annotationsFile)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	s.annotations = list
+	return nil
+}
+
+func (s *Store) AddAnnotation(a domain.Annotation) (domain.Annotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a.ID = 1
+	for _, other := range s.annotations {
+		a.ID = max(a.ID, other.ID+1)
+	}
+	list := append(append([]domain.Annotation{}, s.annotations...), a)
+	return a, s.saveAnnotations(list)
+}
+
+func (s *Store) ListAnnotations(hostID string, start, end time.Time) ([]domain.Annotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return domain.FilterAnnotations(s.annotations, hostID, start, end), nil
+}
+
+func (s *Store) DeleteAnnotation(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]domain.Annotation, 0, len(s.annotations))
+	for _, a := range s.annotations {
+		if a.ID != id {
+			list = append(list, a)
+		}
+	}
+	if len(list) == len(s.annotations) {
+		return domain.ErrAnnotationNotFound
+	}
+	return s.saveAnnotations(list)
+}
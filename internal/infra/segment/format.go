@@ -0,0 +1,156 @@
+package segment
+
+import (
+	"encoding/binary"
+	"lag-monitor/internal/domain"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formato em disco: um diretório por host e um subdiretório por nível. Cada arquivo
+// (segmento) cobre um período fixo e recebe registros de tamanho fixo em little endian,
+// sempre por append. A retenção remove segmentos inteiros.
+//
+//	<dir>/<host>/raw/20261019.seg   um dia (UTC)    rawRecordSize bytes por amostra
+//	<dir>/<host>/1m/202610.seg      um mês (UTC)    rollupRecordSize bytes por minuto
+//	<dir>/<host>/1h/2026.seg        um ano (UTC)    rollupRecordSize bytes por hora
+
+const (
+	rawRecordSize    = 8*3 + 1 // ts (µs), latência, jitter, perda
+	rollupRecordSize = 8 * 11  // intervalo (µs), contagens e latências
+	segmentExt       = ".seg"
+)
+
+// tier descreve como os segmentos de um nível são nomeados
+type tier struct {
+	res        domain.Resolution
+	layout     string // Formato do nome do arquivo
+	recordSize int
+	next       func(t time.Time) time.Time // Início do próximo segmento
+}
+
+var (
+	rawTier = tier{domain.ResolutionRaw, "20060102", rawRecordSize,
+		func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }}
+	minuteTier = tier{domain.ResolutionMinute, "200601", rollupRecordSize,
+		func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }}
+	hourTier = tier{domain.ResolutionHour, "2006", rollupRecordSize,
+		func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }}
+
+	rollupTiers = []tier{minuteTier, hourTier}
+)
+
+func tierOf(res domain.Resolution) (tier, bool) {
+	for _, t := range append([]tier{rawTier}, rollupTiers...) {
+		if t.res == res {
+			return t, true
+		}
+	}
+	return tier{}, false
+}
+
+func (t tier) path(hostDir string, ts time.Time) string {
+	return filepath.Join(hostDir, string(t.res), ts.UTC().Format(t.layout)+segmentExt)
+}
+
+// segment é um arquivo existente de um nível
+type segment struct {
+	path       string
+	start, end time.Time
+	size       int64
+}
+
+// segments lista os arquivos do nível em ordem cronológica
+func (t tier) segments(hostDir string) []segment {
+	entries, _ := os.ReadDir(filepath.Join(hostDir, string(t.res)))
+
+	var out []segment
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), segmentExt)
+		start, err := time.Parse(t.layout, name)
+		if err != nil || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, segment{
+			path:  filepath.Join(hostDir, string(t.res), e.Name()),
+			start: start, end: t.next(start), size: info.Size(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].start.Before(out[j].start) })
+	return out
+}
+
+// overlapping filtra os segmentos que cobrem algum instante do período
+func overlapping(segs []segment, start, end time.Time) []segment {
+	var out []segment
+	for _, s := range segs {
+		if s.end.After(start) && !s.start.After(end) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func encodeRaw(d domain.PingResult) []byte {
+	b := make([]byte, rawRecordSize)
+	binary.LittleEndian.PutUint64(b[0:], uint64(d.Timestamp.UnixMicro()))
+	binary.LittleEndian.PutUint64(b[8:], uint64(d.Latency))
+	binary.LittleEndian.PutUint64(b[16:], uint64(d.Jitter))
+	if d.Loss {
+		b[24] = 1
+	}
+	return b
+}
+
+func decodeRaw(hostID string, b []byte) domain.PingResult {
+	return domain.PingResult{
+		HostID:    hostID,
+		Timestamp: time.UnixMicro(int64(binary.LittleEndian.Uint64(b[0:]))),
+		Latency:   int64(binary.LittleEndian.Uint64(b[8:])),
+		Jitter:    int64(binary.LittleEndian.Uint64(b[16:])),
+		Loss:      b[24] == 1,
+	}
+}
+
+func encodeRollup(r domain.Rollup) []byte {
+	b := make([]byte, rollupRecordSize)
+	for i, v := range []int64{r.Bucket.UnixMicro(), int64(r.Count), int64(r.LossCount),
+		r.LatMin, r.LatAvg, r.LatMax, r.LatP50, r.LatP95, r.LatP99, r.JitterAvg, r.JitterMax} {
+		binary.LittleEndian.PutUint64(b[i*8:], uint64(v))
+	}
+	return b
+}
+
+func decodeRollup(hostID string, res domain.Resolution, b []byte) domain.Rollup {
+	v := func(i int) int64 { return int64(binary.LittleEndian.Uint64(b[i*8:])) }
+	return domain.Rollup{
+		HostID: hostID, Resolution: res, Bucket: time.UnixMicro(v(0)),
+		Count: int(v(1)), LossCount: int(v(2)),
+		LatMin: v(3), LatAvg: v(4), LatMax: v(5),
+		LatP50: v(6), LatP95: v(7), LatP99: v(8),
+		JitterAvg: v(9), JitterMax: v(10),
+	}
+}
+
+// readRecords lê os registros completos do arquivo. Um registro parcial no fim (escrita
+// interrompida) é ignorado.
+func readRecords(path string, size int, fn func(b []byte)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for off := 0; off+size <= len(data); off += size {
+		fn(data[off : off+size])
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package segment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lag-monitor/internal/domain"
+)
+
+func TestRawRecord(t *testing.T) {
+	ts := time.Date(2026, time.October, 19, 13, 45, 7, 123456000, time.UTC)
+	for _, d := range []domain.PingResult{
+		{HostID: "h", Timestamp: ts},
+		{HostID: "h", Timestamp: ts, Latency: 12_345, Jitter: 678},
+		{HostID: "h", Timestamp: ts, Loss: true},
+		{HostID: "h", Timestamp: time.UnixMicro(0), Latency: 1 << 40, Jitter: 1 << 33},
+	} {
+		b := encodeRaw(d)
+		if len(b) != rawRecordSize {
+			t.Fatalf("registro com %d bytes, esperado %d", len(b), rawRecordSize)
+		}
+		got := decodeRaw("h", b)
+		if !got.Timestamp.Equal(d.Timestamp) || got.Latency != d.Latency || got.Jitter != d.Jitter ||
+			got.Loss != d.Loss || got.HostID != d.HostID {
+			t.Errorf("ida e volta: %+v → %+v", d, got)
+		}
+	}
+}
+
+func TestRollupRecord(t *testing.T) {
+	r := domain.Rollup{
+		HostID: "h", Resolution: domain.ResolutionMinute,
+		Bucket: time.Date(2026, time.October, 19, 13, 45, 0, 0, time.UTC),
+		Count:  60, LossCount: 3,
+		LatMin: 1_000, LatAvg: 2_000, LatMax: 9_000,
+		LatP50: 1_900, LatP95: 5_000, LatP99: 8_000,
+		JitterAvg: 300, JitterMax: 2_500,
+	}
+	b := encodeRollup(r)
+	if len(b) != rollupRecordSize {
+		t.Fatalf("registro com %d bytes, esperado %d", len(b), rollupRecordSize)
+	}
+	got := decodeRollup("h", domain.ResolutionMinute, b)
+	if !got.Bucket.Equal(r.Bucket) {
+		t.Errorf("intervalo = %s, esperado %s", got.Bucket, r.Bucket)
+	}
+	got.Bucket = r.Bucket
+	if got != r {
+		t.Errorf("ida e volta: %+v → %+v", r, got)
+	}
+}
+
+func TestTierPath(t *testing.T) {
+	// 23h30 em São Paulo já é o dia seguinte em UTC
+	sp := time.FixedZone("BRT", -3*3600)
+	late := time.Date(2026, time.December, 31, 23, 30, 0, 0, sp)
+
+	tests := []struct {
+		tier tier
+		ts   time.Time
+		want string
+	}{
+		{rawTier, time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC), "raw/20261019.seg"},
+		{minuteTier, time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC), "1m/202610.seg"},
+		{hourTier, time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC), "1h/2026.seg"},
+		{rawTier, late, "raw/20270101.seg"},
+		{minuteTier, late, "1m/202701.seg"},
+		{hourTier, late, "1h/2027.seg"},
+	}
+	for _, tt := range tests {
+		if got := tt.tier.path("host", tt.ts); got != filepath.Join("host", tt.want) {
+			t.Errorf("%s em %s: %s, esperado %s", tt.tier.res, tt.ts, got, tt.want)
+		}
+	}
+}
+
+func TestSegments(t *testing.T) {
+	dir := t.TempDir()
+	raw := filepath.Join(dir, string(domain.ResolutionRaw))
+	if err := os.MkdirAll(filepath.Join(raw, "20261001.seg"), 0755); err != nil { // Diretório: ignorado
+		t.Fatal(err)
+	}
+	for name, size := range map[string]int{
+		"20261019.seg": 2 * rawRecordSize,
+		"20261017.seg": rawRecordSize,
+		"20261018.seg": 0,
+		"lixo.seg":     rawRecordSize, // Nome fora do formato: ignorado
+		"20261020.tmp": rawRecordSize,
+	} {
+		if err := os.WriteFile(filepath.Join(raw, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 0, 0, 0, 0, time.UTC) }
+	segs := rawTier.segments(dir)
+	want := []segment{
+		{filepath.Join(raw, "20261017.seg"), day(17), day(18), rawRecordSize},
+		{filepath.Join(raw, "20261018.seg"), day(18), day(19), 0},
+		{filepath.Join(raw, "20261019.seg"), day(19), day(20), 2 * rawRecordSize},
+	}
+	if len(segs) != len(want) {
+		t.Fatalf("segmentos = %+v, esperado %+v", segs, want)
+	}
+	for i := range want {
+		if segs[i].path != want[i].path || !segs[i].start.Equal(want[i].start) ||
+			!segs[i].end.Equal(want[i].end) || segs[i].size != want[i].size {
+			t.Errorf("segmento %d = %+v, esperado %+v", i, segs[i], want[i])
+		}
+	}
+
+	tests := []struct {
+		start, end time.Time
+		want       int
+	}{
+		{day(17), day(17).Add(time.Hour), 1},
+		{day(17).Add(time.Hour), day(18), 2}, // O fim é inclusivo
+		{day(18).Add(-time.Microsecond), day(19).Add(-time.Microsecond), 2},
+		{day(15), day(16), 0},
+		{day(20), day(21), 0},
+		{day(1), day(31), 3},
+	}
+	for _, tt := range tests {
+		if got := overlapping(segs, tt.start, tt.end); len(got) != tt.want {
+			t.Errorf("overlapping(%s, %s) = %d segmentos, esperado %d", tt.start, tt.end, len(got), tt.want)
+		}
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	dir := t.TempDir()
+	ts := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		records int
+		partial int // Bytes de um registro incompleto no fim
+	}{
+		{"vazio", 0, 0},
+		{"completos", 3, 0},
+		{"escrita interrompida", 2, rawRecordSize / 2},
+		{"só registro parcial", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data []byte
+			for i := 0; i < tt.records; i++ {
+				data = append(data, encodeRaw(domain.PingResult{Timestamp: ts.Add(time.Duration(i) * time.Second), Latency: int64(i)})...)
+			}
+			data = append(data, make([]byte, tt.partial)...)
+			path := filepath.Join(dir, tt.name+segmentExt)
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []domain.PingResult
+			if err := readRecords(path, rawRecordSize, func(b []byte) { got = append(got, decodeRaw("h", b)) }); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.records {
+				t.Fatalf("registros = %d, esperado %d", len(got), tt.records)
+			}
+			for i, d := range got {
+				if d.Latency != int64(i) || !d.Timestamp.Equal(ts.Add(time.Duration(i)*time.Second)) {
+					t.Errorf("registro %d = %+v", i, d)
+				}
+			}
+		})
+	}
+
+	if err := readRecords(filepath.Join(dir, "inexistente.seg"), rawRecordSize, func([]byte) {}); err != nil {
+		t.Errorf("arquivo inexistente: %v", err)
+	}
+}
+
+func TestStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, time.October, 19, 23, 58, 0, 0, time.UTC)
+
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Atravessa a virada do dia: as amostras vão para dois segmentos
+	var batch []domain.PingResult
+	for i := 0; i < 240; i++ {
+		batch = append(batch, domain.PingResult{
+			HostID: "host/1", Timestamp: start.Add(time.Duration(i) * time.Second),
+			Latency: 10_000, Loss: i%60 == 0,
+		})
+	}
+	if err := s.SaveBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	got, err := s.GetHistory("host/1", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(batch) {
+		t.Fatalf("amostras = %d, esperado %d", len(got), len(batch))
+	}
+	for i := range got {
+		if !got[i].Timestamp.Equal(batch[i].Timestamp) || got[i].Loss != batch[i].Loss {
+			t.Fatalf("amostra %d = %+v, esperado %+v", i, got[i], batch[i])
+		}
+	}
+	if segs := rawTier.segments(s.hostDir("host/1")); len(segs) != 2 {
+		t.Errorf("segmentos brutos = %d, esperado 2", len(segs))
+	}
+
+	// Os minutos fechados antes do encerramento têm agregado
+	minutes, err := s.GetRollups("host/1", domain.ResolutionMinute, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(minutes) < 3 {
+		t.Fatalf("agregados por minuto = %d, esperado ao menos 3", len(minutes))
+	}
+	for _, r := range minutes[:3] {
+		if r.Count != 60 || r.LossCount != 1 {
+			t.Errorf("minuto %s: %d amostras, %d perdas; esperado 60 e 1", r.Bucket, r.Count, r.LossCount)
+		}
+	}
+}
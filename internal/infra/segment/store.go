@@ -0,0 +1,445 @@
+package segment
+
+import (
+	"errors"
+	"fmt"
+	"lag-monitor/internal/domain"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store grava as séries em segmentos append-only (ver format.go). Amostras vão direto
+// para o segmento do dia; agregados de minuto e hora são gravados quando o intervalo
+// fecha. Na abertura, intervalos fechados que ficaram sem agregado (encerramento
+// abrupto) são recalculados a partir das amostras brutas.
+type Store struct {
+	dir string
+
+	mu     sync.Mutex
+	hosts  map[string]*hostState
+	closed bool
+	freed  int64 // Bytes removidos pela retenção desde o último Compact
+
+	annotations []domain.Annotation // Espelho de annotationsFile
+}
+
+// hostState é o estado de escrita de um host. Só é acessado com Store.mu.
+type hostState struct {
+	id      string
+	dir     string
+	files   map[domain.Resolution]*os.File // Segmento aberto para append em cada nível
+	pending map[domain.Resolution]*pendingBucket
+	written map[domain.Resolution]time.Time // Último intervalo gravado
+}
+
+// pendingBucket acumula as amostras do intervalo ainda aberto
+type pendingBucket struct {
+	start   time.Time
+	samples []domain.PingResult
+}
+
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := &Store{dir: dir, hosts: make(map[string]*hostState)}
+	if err := s.loadAnnotations(); err != nil {
+		return nil, fmt.Errorf("ler anotações: %w", err)
+	}
+
+	// Recupera os agregados de todos os hosts existentes
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if id, ok := hostID(e); ok {
+			if _, err := s.host(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+func hostID(e os.DirEntry) (string, bool) {
+	if !e.IsDir() {
+		return "", false
+	}
+	id, err := url.PathUnescape(e.Name())
+	return id, err == nil
+}
+
+func (s *Store) hostDir(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id))
+}
+
+// host retorna o estado do host, criando-o e recuperando agregados pendentes na
+// primeira vez. Requer s.mu.
+func (s *Store) host(id string) (*hostState, error) {
+	if h, ok := s.hosts[id]; ok {
+		return h, nil
+	}
+
+	h := &hostState{
+		id:      id,
+		dir:     s.hostDir(id),
+		files:   make(map[domain.Resolution]*os.File),
+		pending: make(map[domain.Resolution]*pendingBucket),
+		written: make(map[domain.Resolution]time.Time),
+	}
+	if err := os.MkdirAll(h.dir, 0755); err != nil {
+		return nil, err
+	}
+	s.hosts[id] = h
+
+	if err := h.recover(); err != nil {
+		return nil, fmt.Errorf("recuperar agregados de %s: %w", id, err)
+	}
+	return h, nil
+}
+
+// recover descobre o último intervalo gravado de cada nível e reprocessa as amostras
+// brutas posteriores a ele
+func (h *hostState) recover() error {
+	since := time.Time{}
+	for _, t := range rollupTiers {
+		segs := t.segments(h.dir)
+		if len(segs) > 0 {
+			last := segs[len(segs)-1]
+			readRecords(last.path, t.recordSize, func(b []byte) {
+				if r := decodeRollup(h.id, t.res, b); r.Bucket.After(h.written[t.res]) {
+					h.written[t.res] = r.Bucket
+				}
+			})
+		}
+		if w := h.written[t.res]; since.IsZero() || w.Before(since) {
+			since = w
+		}
+	}
+
+	data, err := h.history(since, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, d := range data {
+		if err := h.feedRollups(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendRecord grava o registro no segmento do nível que contém ts
+func (h *hostState) appendRecord(t tier, ts time.Time, rec []byte) error {
+	path := t.path(h.dir, ts)
+	f := h.files[t.res]
+	if f == nil || f.Name() != path {
+		if f != nil {
+			f.Close()
+		}
+		var err error
+		if f, err = openSegment(path, t.recordSize); err != nil {
+			delete(h.files, t.res)
+			return err
+		}
+		h.files[t.res] = f
+	}
+	_, err := f.Write(rec)
+	return err
+}
+
+// openSegment abre o arquivo para append, descartando um registro parcial no fim
+// para manter o alinhamento dos próximos
+func openSegment(path string, recordSize int) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if extra := info.Size() % int64(recordSize); extra != 0 {
+		if err := f.Truncate(info.Size() - extra); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// feedRollups acumula a amostra nos intervalos abertos e grava os que fecharam.
+// Amostras anteriores ao intervalo aberto (fora de ordem) não alteram agregados já gravados.
+func (h *hostState) feedRollups(d domain.PingResult) error {
+	for _, t := range rollupTiers {
+		b := domain.BucketStart(d.Timestamp, t.res.Duration())
+		if !b.After(h.written[t.res]) && !h.written[t.res].IsZero() {
+			continue
+		}
+
+		p := h.pending[t.res]
+		if p != nil && b.Before(p.start) {
+			continue
+		}
+		if p != nil && b.After(p.start) {
+			if err := h.writeRollup(t, p); err != nil {
+				return err
+			}
+			p = nil
+		}
+		if p == nil {
+			p = &pendingBucket{start: b}
+			h.pending[t.res] = p
+		}
+		p.samples = append(p.samples, d)
+	}
+	return nil
+}
+
+func (h *hostState) writeRollup(t tier, p *pendingBucket) error {
+	r := domain.BuildRollup(h.id, t.res, p.start, p.samples)
+	if err := h.appendRecord(t, p.start, encodeRollup(r)); err != nil {
+		return err
+	}
+	h.written[t.res] = p.start
+	delete(h.pending, t.res)
+	return nil
+}
+
+// history lê as amostras brutas do período em ordem cronológica
+func (h *hostState) history(start, end time.Time) ([]domain.PingResult, error) {
+	var out []domain.PingResult
+	for _, seg := range overlapping(rawTier.segments(h.dir), start, end) {
+		err := readRecords(seg.path, rawRecordSize, func(b []byte) {
+			if d := decodeRaw(h.id, b); !d.Timestamp.Before(start) && !d.Timestamp.After(end) {
+				out = append(out, d)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	return out, nil
+}
+
+func (s *Store) SaveBatch(results []domain.PingResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return domain.ErrStorageClosed
+	}
+
+	var errs []error
+	for _, d := range results {
+		h, err := s.host(d.HostID)
+		if err == nil {
+			err = h.appendRecord(rawTier, d.Timestamp, encodeRaw(d))
+		}
+		if err == nil {
+			err = h.feedRollups(d)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close grava os segmentos em disco. Intervalos ainda abertos não são gravados: serão
+// recalculados das amostras brutas na próxima abertura.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var errs []error
+	for _, h := range s.hosts {
+		for _, f := range h.files {
+			errs = append(errs, f.Sync(), f.Close())
+		}
+		h.files = nil
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Store) GetHistory(hostID string, start, end time.Time) ([]domain.PingResult, error) {
+	h := &hostState{id: hostID, dir: s.hostDir(hostID)}
+	return h.history(start, end)
+}
+
+// GetRollups retorna os agregados gravados e o do intervalo ainda aberto
+func (s *Store) GetRollups(hostID string, res domain.Resolution, start, end time.Time) ([]domain.Rollup, error) {
+	t, ok := tierOf(res)
+	if !ok || t.res == domain.ResolutionRaw {
+		return nil, fmt.Errorf("resolução %q não possui agregados", res)
+	}
+	start = domain.BucketStart(start, res.Duration())
+
+	var out []domain.Rollup
+	for _, seg := range overlapping(t.segments(s.hostDir(hostID)), start, end) {
+		err := readRecords(seg.path, t.recordSize, func(b []byte) {
+			if r := decodeRollup(hostID, res, b); !r.Bucket.Before(start) && !r.Bucket.After(end) {
+				out = append(out, r)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	if h, ok := s.hosts[hostID]; ok {
+		if p := h.pending[res]; p != nil && !p.start.Before(start) && !p.start.After(end) {
+			out = append(out, domain.BuildRollup(hostID, res, p.start, p.samples))
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Bucket.Before(out[j].Bucket) })
+	return out, nil
+}
+
+func (s *Store) GetStats(hostID string, res domain.Resolution, start, end time.Time) (domain.RangeStats, error) {
+	if res == domain.ResolutionRaw {
+		data, err := s.GetHistory(hostID, start, end)
+		return domain.RangeStatsOf(hostID, data, start, end), err
+	}
+
+	rollups, err := s.GetRollups(hostID, res, start, end)
+	if err != nil || len(rollups) == 0 {
+		return domain.RangeStats{HostID: hostID, Resolution: res, Start: start, End: end}, err
+	}
+	st := domain.StatsFromRollup(domain.MergeRollups(hostID, res, rollups, start, 0)[0], end)
+	st.Start = start
+	return st, nil
+}
+
+func (s *Store) GetSeries(hostID string, res domain.Resolution, start, end time.Time, bucket time.Duration) ([]domain.Rollup, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("intervalo inválido: %s", bucket)
+	}
+	if res == domain.ResolutionRaw {
+		data, err := s.GetHistory(hostID, start, end)
+		return domain.Series(hostID, data, start, bucket), err
+	}
+
+	rollups, err := s.GetRollups(hostID, res, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return domain.MergeRollups(hostID, res, rollups, start, bucket), nil
+}
+
+func (s *Store) GetHistogram(hostID string, start, end time.Time, binWidth int64) ([]domain.HistogramBin, error) {
+	if binWidth <= 0 {
+		return nil, fmt.Errorf("largura de faixa inválida: %d", binWidth)
+	}
+	data, err := s.GetHistory(hostID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Histogram(data, binWidth), nil
+}
+
+// CleanOldData remove os segmentos de amostras inteiramente anteriores ao corte. Um
+// segmento cobre um dia, então a retenção tem granularidade de um dia.
+func (s *Store) CleanOldData(days int, overrides map[string]int) (int64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		id, ok := hostID(e)
+		if !ok {
+			continue
+		}
+		d := days
+		if o, ok := overrides[id]; ok {
+			d = o
+		}
+		n, err := s.removeSegments(rawTier, s.hostDir(id), d)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// CleanOldRollups remove os segmentos de agregados inteiramente anteriores ao corte
+// (um mês para minutos, um ano para horas)
+func (s *Store) CleanOldRollups(res domain.Resolution, days int) (int64, error) {
+	t, ok := tierOf(res)
+	if !ok || t.res == domain.ResolutionRaw {
+		return 0, fmt.Errorf("resolução %q não possui agregados", res)
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		if _, ok := hostID(e); !ok {
+			continue
+		}
+		n, err := s.removeSegments(t, filepath.Join(s.dir, e.Name()), days)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// removeSegments apaga os segmentos do nível que terminam antes do corte e retorna
+// quantos registros foram removidos
+func (s *Store) removeSegments(t tier, hostDir string, days int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	var rows int64
+	for _, seg := range t.segments(hostDir) {
+		if seg.end.After(cutoff) {
+			break
+		}
+		if err := os.Remove(seg.path); err != nil {
+			return rows, err
+		}
+		rows += seg.size / int64(t.recordSize)
+
+		s.mu.Lock()
+		s.freed += seg.size
+		s.mu.Unlock()
+	}
+	return rows, nil
+}
+
+// Compact retorna o espaço liberado pela retenção desde a última chamada. Segmentos
+// são apagados por inteiro, então não há o que compactar.
+func (s *Store) Compact() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	freed := s.freed
+	s.freed = 0
+	return freed, nil
+}
@@ -0,0 +1,278 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"lag-monitor/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tamanho máximo de uma linha nos formatos lidos linha a linha
+const maxLineSize = 1 << 20
+
+// BOM que planilhas (Excel) gravam no início de arquivos UTF-8
+const bom = "\ufeff"
+
+var errInvalid = errors.New("linha inválida")
+
+// Decode lê as amostras de r e chama fn para cada uma, na ordem do arquivo. Linhas
+// que não puderem ser interpretadas são contadas em invalid e ignoradas; um erro de
+// fn interrompe a leitura. hostID é usado nas amostras sem host (obrigatório para a
+// planilha do relatório, que não tem essa coluna).
+func Decode(r io.Reader, f Format, hostID string, fn func(domain.PingResult) error) (invalid int, err error) {
+	switch f {
+	case FormatCSV:
+		return decodeCSV(r, hostID, fn)
+	case FormatJSONL:
+		return decodeLines(r, func(line string) (domain.PingResult, error) { return parseJSONLine(line, hostID) }, fn)
+	case FormatInflux:
+		return decodeLines(r, func(line string) (domain.PingResult, error) { return parseInfluxLine(line, hostID) }, fn)
+	case FormatReport:
+		if hostID == "" {
+			return 0, errors.New("informe o host: a planilha do relatório não tem essa coluna")
+		}
+		return decodeLines(r, func(line string) (domain.PingResult, error) { return parseReportLine(line, hostID) }, fn)
+	case FormatParquet:
+		return 0, errors.New("o formato parquet é apenas para exportação")
+	}
+	return 0, fmt.Errorf("formato desconhecido: %q", f)
+}
+
+// errSkip marca linhas que não são dados (cabeçalho, comentários)
+var errSkip = errors.New("ignorar")
+
+func decodeLines(r io.Reader, parse func(string) (domain.PingResult, error), fn func(domain.PingResult) error) (int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	invalid := 0
+	for sc.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(sc.Text(), bom))
+		if line == "" {
+			continue
+		}
+		d, err := parse(line)
+		if errors.Is(err, errSkip) {
+			continue
+		} else if err != nil {
+			invalid++
+			continue
+		}
+		if err := fn(d); err != nil {
+			return invalid, err
+		}
+	}
+	return invalid, sc.Err()
+}
+
+func parseJSONLine(line, hostID string) (domain.PingResult, error) {
+	var d domain.PingResult
+	if err := json.Unmarshal([]byte(line), &d); err != nil {
+		return d, err
+	}
+	return withHost(d, hostID)
+}
+
+func parseReportLine(line, hostID string) (domain.PingResult, error) {
+	if strings.HasPrefix(line, "TIMESTAMP;") {
+		return domain.PingResult{}, errSkip
+	}
+	parts := strings.Split(line, ";")
+	if len(parts) != 4 {
+		return domain.PingResult{}, errInvalid
+	}
+
+	ts, err := time.ParseInLocation(reportLayout, parts[0], time.Local)
+	if err != nil {
+		return domain.PingResult{}, err
+	}
+	lat, err1 := strconv.ParseInt(parts[1], 10, 64)
+	jit, err2 := strconv.ParseInt(parts[2], 10, 64)
+	loss, err3 := strconv.ParseBool(parts[3])
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return domain.PingResult{}, err
+	}
+	return domain.PingResult{HostID: hostID, Timestamp: ts, Latency: lat * 1000, Jitter: jit * 1000, Loss: loss}, nil
+}
+
+// decodeCSV localiza as colunas pelo cabeçalho, se houver; sem cabeçalho assume a ordem
+// da exportação
+func decodeCSV(r io.Reader, hostID string, fn func(domain.PingResult) error) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	cols := map[string]int{}
+	for i, name := range strings.Split(csvHeader, ",") {
+		cols[name] = i
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	invalid, first := 0, true
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return invalid, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			invalid++
+			continue
+		} else if err != nil {
+			return invalid, err
+		}
+
+		if first {
+			first = false
+			if hasColumn(rec, "timestamp") {
+				cols = map[string]int{}
+				for i, name := range rec {
+					cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, bom)))] = i
+				}
+				continue
+			}
+		}
+
+		d, err := parseCSVRecord(field, rec, hostID)
+		if err != nil {
+			invalid++
+			continue
+		}
+		if err := fn(d); err != nil {
+			return invalid, err
+		}
+	}
+}
+
+func hasColumn(rec []string, name string) bool {
+	for _, c := range rec {
+		if strings.EqualFold(strings.TrimSpace(c), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCSVRecord(field func([]string, string) string, rec []string, hostID string) (domain.PingResult, error) {
+	ts, err := time.Parse(time.RFC3339Nano, field(rec, "timestamp"))
+	if err != nil {
+		return domain.PingResult{}, err
+	}
+	lat, err1 := strconv.ParseInt(field(rec, "latency_us"), 10, 64)
+	jit, err2 := strconv.ParseInt(field(rec, "jitter_us"), 10, 64)
+	loss, err3 := strconv.ParseBool(field(rec, "loss"))
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return domain.PingResult{}, err
+	}
+	return withHost(domain.PingResult{HostID: field(rec, "host_id"), Timestamp: ts, Latency: lat, Jitter: jit, Loss: loss}, hostID)
+}
+
+// parseInfluxLine interpreta "ping,host=<id> latency=<µs>i,jitter=<µs>i,loss=<bool> <ns>".
+// Outras medições e linhas sem timestamp são inválidas.
+func parseInfluxLine(line, hostID string) (domain.PingResult, error) {
+	if strings.HasPrefix(line, "#") {
+		return domain.PingResult{}, errSkip
+	}
+	parts := splitUnescaped(line, ' ')
+	if len(parts) != 3 {
+		return domain.PingResult{}, errInvalid
+	}
+
+	key := splitUnescaped(parts[0], ',')
+	if unescapeInflux(key[0]) != influxMeasurement {
+		return domain.PingResult{}, errInvalid
+	}
+	var d domain.PingResult
+	for _, tag := range key[1:] {
+		kv := splitUnescaped(tag, '=')
+		if len(kv) == 2 && unescapeInflux(kv[0]) == "host" {
+			d.HostID = unescapeInflux(kv[1])
+		}
+	}
+
+	seen := 0
+	for _, f := range splitUnescaped(parts[1], ',') {
+		kv := splitUnescaped(f, '=')
+		if len(kv) != 2 {
+			return d, errInvalid
+		}
+		var err error
+		switch unescapeInflux(kv[0]) {
+		case "latency":
+			d.Latency, err = influxInt(kv[1])
+			seen++
+		case "jitter":
+			d.Jitter, err = influxInt(kv[1])
+		case "loss":
+			d.Loss, err = strconv.ParseBool(kv[1])
+		}
+		if err != nil {
+			return d, err
+		}
+	}
+	if seen == 0 {
+		return d, errInvalid
+	}
+
+	ns, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return d, err
+	}
+	d.Timestamp = time.Unix(0, ns)
+	return withHost(d, hostID)
+}
+
+// influxInt aceita inteiros (sufixo i) e floats, arredondando para baixo
+func influxInt(s string) (int64, error) {
+	if v, ok := strings.CutSuffix(s, "i"); ok {
+		return strconv.ParseInt(v, 10, 64)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return int64(f), err
+}
+
+// splitUnescaped divide s em sep, exceto quando o separador vem escapado com \ ou
+// dentro de aspas
+func splitUnescaped(s string, sep byte) []string {
+	var out []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
+
+var influxUnescaper = strings.NewReplacer(`\,`, ",", `\ `, " ", `\=`, "=")
+
+func unescapeInflux(s string) string {
+	return influxUnescaper.Replace(s)
+}
+
+func withHost(d domain.PingResult, hostID string) (domain.PingResult, error) {
+	if d.HostID == "" {
+		d.HostID = hostID
+	}
+	if d.HostID == "" || d.Timestamp.IsZero() {
+		return d, errInvalid
+	}
+	return d, nil
+}
@@ -0,0 +1,85 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"lag-monitor/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encoder grava amostras em um formato portável. Flush deve ser chamado ao final
+// (no Parquet, grava o rodapé e encerra o arquivo).
+type Encoder struct {
+	w      *bufio.Writer
+	format Format
+	header bool
+	csv    *csv.Writer
+	json   *json.Encoder
+	pq     *parquetWriter[parquetRaw]
+}
+
+func NewEncoder(w io.Writer, f Format) *Encoder {
+	bw := bufio.NewWriter(w)
+	e := &Encoder{w: bw, format: f, csv: csv.NewWriter(bw), json: json.NewEncoder(bw)}
+	if f == FormatParquet {
+		e.pq = newParquetWriter[parquetRaw](bw, influxMeasurement)
+	}
+	return e
+}
+
+func (e *Encoder) Write(d domain.PingResult) error {
+	if !e.header {
+		e.header = true
+		switch e.format {
+		case FormatCSV:
+			e.csv.Write(strings.Split(csvHeader, ","))
+		case FormatReport:
+			e.w.WriteString(reportHeader + "\n")
+		}
+	}
+
+	switch e.format {
+	case FormatCSV:
+		return e.csv.Write([]string{d.HostID, d.Timestamp.UTC().Format(time.RFC3339Nano),
+			strconv.FormatInt(d.Latency, 10), strconv.FormatInt(d.Jitter, 10), strconv.FormatBool(d.Loss)})
+	case FormatJSONL:
+		return e.json.Encode(d)
+	case FormatInflux:
+		_, err := fmt.Fprintf(e.w, "%s,host=%s latency=%di,jitter=%di,loss=%t %d\n", influxMeasurement,
+			influxEscape(d.HostID), d.Latency, d.Jitter, d.Loss, d.Timestamp.UnixNano())
+		return err
+	case FormatParquet:
+		return e.pq.write(toParquetRaw(d))
+	case FormatReport:
+		// Mesmo layout da planilha do relatório
+		_, err := fmt.Fprintf(e.w, "%s;%d;%d;%v\n",
+			d.Timestamp.Format(reportLayout), d.Latency/1000, d.Jitter/1000, d.Loss)
+		return err
+	}
+	return fmt.Errorf("formato desconhecido: %q", e.format)
+}
+
+func (e *Encoder) Flush() error {
+	if e.pq != nil {
+		if err := e.pq.close(); err != nil {
+			return err
+		}
+	}
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+var influxEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+
+// influxEscape escapa vírgula, espaço e igual em valores de tags
+func influxEscape(s string) string {
+	return influxEscaper.Replace(s)
+}
@@ -0,0 +1,99 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Format identifica um formato portável de histórico bruto
+type Format string
+
+const (
+	FormatCSV     Format = "csv"     // host_id,timestamp,latency_us,jitter_us,loss
+	FormatJSONL   Format = "jsonl"   // Um PingResult em JSON por linha
+	FormatInflux  Format = "influx"  // Line protocol do InfluxDB (timestamp em ns)
+	FormatParquet Format = "parquet" // Colunar, apenas exportação
+	// Planilha gerada junto do relatório (DADOS-TECNICOS-<host>-<unix>.csv): um host
+	// por arquivo, horário local com precisão de segundos e valores em ms
+	FormatReport Format = "dados-tecnicos"
+)
+
+// Nome da medição e cabeçalhos usados na exportação
+const (
+	influxMeasurement = "ping"
+	csvHeader         = "host_id,timestamp,latency_us,jitter_us,loss"
+	reportHeader      = "TIMESTAMP;LATENCY_MS;JITTER_MS;LOSS"
+	reportLayout      = "2006-01-02 15:04:05"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatCSV, FormatJSONL, FormatInflux, FormatParquet, FormatReport:
+		return f, nil
+	case "ndjson", "json":
+		return FormatJSONL, nil
+	case "line", "lp":
+		return FormatInflux, nil
+	}
+	return "", fmt.Errorf("formato desconhecido: %q", s)
+}
+
+// Extension é a extensão usada ao exportar
+func (f Format) Extension() string {
+	switch f {
+	case FormatJSONL:
+		return ".jsonl"
+	case FormatInflux:
+		return ".lp"
+	case FormatParquet:
+		return ".parquet"
+	}
+	return ".csv"
+}
+
+// Precision é a resolução dos timestamps do formato: amostras importadas a menos disso
+// de uma existente são tratadas como a mesma
+func (f Format) Precision() time.Duration {
+	if f == FormatReport {
+		return time.Second
+	}
+	return time.Microsecond
+}
+
+// Detect deduz o formato pela extensão do arquivo e, se preciso, pelo conteúdo inicial
+func Detect(path string, head []byte) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return FormatJSONL
+	case ".lp", ".line", ".influx":
+		return FormatInflux
+	case ".parquet":
+		return FormatParquet
+	}
+
+	head = bytes.TrimLeft(head, bom+" \t\r\n")
+	switch {
+	case bytes.HasPrefix(head, []byte("{")):
+		return FormatJSONL
+	case bytes.HasPrefix(head, []byte("TIMESTAMP;")):
+		return FormatReport
+	case bytes.HasPrefix(head, []byte(influxMeasurement+",")):
+		return FormatInflux
+	}
+	return FormatCSV
+}
+
+var reportName = regexp.MustCompile(`^DADOS-TECNICOS-(.+)-\d+\.csv$`)
+
+// HostFromReportName extrai o host do nome gerado pelo relatório
+// (DADOS-TECNICOS-<host>-<unix>.csv)
+func HostFromReportName(path string) string {
+	if m := reportName.FindStringSubmatch(filepath.Base(path)); m != nil {
+		return m[1]
+	}
+	return ""
+}
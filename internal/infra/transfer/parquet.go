@@ -0,0 +1,112 @@
+package transfer
+
+import (
+	"io"
+	"lag-monitor/internal/domain"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+)
+
+const (
+	// Linhas por row group: o gravador só mantém em memória o grupo atual
+	parquetRowGroup = 64 * 1024
+	// Linhas repassadas ao gravador por chamada
+	parquetBatch = 1024
+)
+
+// Colunas da exportação bruta. Timestamps são TIMESTAMP(MICROS, UTC), lidos como
+// datetime pelo DuckDB/Pandas.
+type parquetRaw struct {
+	HostID    string `parquet:"host_id,dict"`
+	Timestamp int64  `parquet:"ts,timestamp(microsecond:utc)"`
+	LatencyUs int64  `parquet:"latency_us"`
+	JitterUs  int64  `parquet:"jitter_us"`
+	Loss      bool   `parquet:"loss"`
+}
+
+// Colunas da exportação de agregados (um registro por intervalo)
+type parquetRollup struct {
+	HostID      string `parquet:"host_id,dict"`
+	Resolution  string `parquet:"resolution,dict"`
+	Bucket      int64  `parquet:"bucket,timestamp(microsecond:utc)"`
+	Count       int64  `parquet:"count"`
+	LossCount   int64  `parquet:"loss_count"`
+	LatMinUs    int64  `parquet:"lat_min_us"`
+	LatAvgUs    int64  `parquet:"lat_avg_us"`
+	LatMaxUs    int64  `parquet:"lat_max_us"`
+	LatP50Us    int64  `parquet:"lat_p50_us"`
+	LatP95Us    int64  `parquet:"lat_p95_us"`
+	LatP99Us    int64  `parquet:"lat_p99_us"`
+	JitterAvgUs int64  `parquet:"jitter_avg_us"`
+	JitterMaxUs int64  `parquet:"jitter_max_us"`
+}
+
+// parquetWriter acumula linhas em lotes pequenos e grava um row group a cada
+// parquetRowGroup linhas
+type parquetWriter[T any] struct {
+	w     *parquet.GenericWriter[T]
+	batch []T
+}
+
+func newParquetWriter[T any](w io.Writer, name string) *parquetWriter[T] {
+	return &parquetWriter[T]{
+		w: parquet.NewGenericWriter[T](w,
+			parquet.NewSchema(name, parquet.SchemaOf(new(T))),
+			parquet.Compression(&zstd.Codec{}),
+			parquet.MaxRowsPerRowGroup(parquetRowGroup)),
+		batch: make([]T, 0, parquetBatch),
+	}
+}
+
+func (p *parquetWriter[T]) write(row T) error {
+	p.batch = append(p.batch, row)
+	if len(p.batch) < parquetBatch {
+		return nil
+	}
+	return p.flush()
+}
+
+func (p *parquetWriter[T]) flush() error {
+	if len(p.batch) == 0 {
+		return nil
+	}
+	_, err := p.w.Write(p.batch)
+	p.batch = p.batch[:0]
+	return err
+}
+
+// close grava o que resta e o rodapé do arquivo
+func (p *parquetWriter[T]) close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+	return p.w.Close()
+}
+
+func toParquetRaw(d domain.PingResult) parquetRaw {
+	return parquetRaw{HostID: d.HostID, Timestamp: d.Timestamp.UnixMicro(), LatencyUs: d.Latency, JitterUs: d.Jitter, Loss: d.Loss}
+}
+
+// RollupEncoder grava agregados em Parquet. Close deve ser chamado ao final.
+type RollupEncoder struct {
+	pq *parquetWriter[parquetRollup]
+}
+
+func NewRollupEncoder(w io.Writer) *RollupEncoder {
+	return &RollupEncoder{pq: newParquetWriter[parquetRollup](w, "ping_rollup")}
+}
+
+func (e *RollupEncoder) Write(r domain.Rollup) error {
+	return e.pq.write(parquetRollup{
+		HostID: r.HostID, Resolution: string(r.Resolution), Bucket: r.Bucket.UnixMicro(),
+		Count: int64(r.Count), LossCount: int64(r.LossCount),
+		LatMinUs: r.LatMin, LatAvgUs: r.LatAvg, LatMaxUs: r.LatMax,
+		LatP50Us: r.LatP50, LatP95Us: r.LatP95, LatP99Us: r.LatP99,
+		JitterAvgUs: r.JitterAvg, JitterMaxUs: r.JitterMax,
+	})
+}
+
+func (e *RollupEncoder) Close() error {
+	return e.pq.close()
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"lag-monitor/internal/domain"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Intervalo entre as verificações da rede local
+const networkCheckInterval = 30 * time.Second
+
+var errNoAnnotations = errors.New("o armazenamento atual não suporta anotações")
+
+func (s *MonitorService) annotationStore() (domain.AnnotationStore, error) {
+	store, ok := s.repo.(domain.AnnotationStore)
+	if !ok {
+		return nil, errNoAnnotations
+	}
+	return store, nil
+}
+
+// AddAnnotation grava uma anotação na linha do tempo. Sem Kind, é do usuário.
+func (s *MonitorService) AddAnnotation(a domain.Annotation) (domain.Annotation, error) {
+	store, err := s.annotationStore()
+	if err != nil {
+		return a, err
+	}
+
+	a.Text = strings.TrimSpace(a.Text)
+	if a.Text == "" {
+		return a, fmt.Errorf("a anotação precisa de um texto")
+	}
+	if a.Start.IsZero() {
+		a.Start = time.Now()
+	}
+	if a.End != nil && a.End.Before(a.Start) {
+		return a, fmt.Errorf("o fim da anotação é anterior ao início")
+	}
+	if a.Kind == "" {
+		a.Kind = domain.AnnotationUser
+	}
+	a.CreatedAt = time.Now()
+
+	created, err := store.AddAnnotation(a)
+	if err != nil {
+		return a, err
+	}
+	s.emit("annotation:created", created)
+	return created, nil
+}
+
+// ListAnnotations retorna as anotações do período (as do host e as globais)
+func (s *MonitorService) ListAnnotations(hostID string, start, end time.Time) ([]domain.Annotation, error) {
+	store, err := s.annotationStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.ListAnnotations(hostID, start, end)
+}
+
+func (s *MonitorService) DeleteAnnotation(id int64) error {
+	store, err := s.annotationStore()
+	if err != nil {
+		return err
+	}
+	if err := store.DeleteAnnotation(id); err != nil {
+		return err
+	}
+	s.emit("annotation:deleted", map[string]int64{"id": id})
+	return nil
+}
+
+// annotate registra uma anotação automática; falhas só vão para o log
+func (s *MonitorService) annotate(hostID, kind, text string) {
+	if _, err := s.annotationStore(); err != nil {
+		return
+	}
+	if _, err := s.AddAnnotation(domain.Annotation{HostID: hostID, Kind: kind, Text: text}); err != nil {
+		log.Printf("anotação automática (%s): %v", kind, err)
+	}
+}
+
+// AnnotateConfigChanges registra as alterações de configuração (hostID vazio para as globais)
+func (s *MonitorService) AnnotateConfigChanges(hostID string, changes []string) {
+	if len(changes) > 0 {
+		s.annotate(hostID, domain.AnnotationConfig, "Configuração alterada: "+strings.Join(changes, "; "))
+	}
+}
+
+// SetNetworkProbe define como a rede local é observada (nil desativa)
+func (s *MonitorService) SetNetworkProbe(p domain.NetworkProbe) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.netProbe = p
+}
+
+// StartNetworkWatch anota as mudanças nas interfaces de rede locais (troca de Wi-Fi,
+// cabo conectado, novo endereço) até o serviço parar
+func (s *MonitorService) StartNetworkWatch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	probe := s.netProbe
+	if probe == nil {
+		return
+	}
+
+	routes, _ := probe.(domain.RouteProbe)
+
+	s.goTracked(func() {
+		last, err := probe.LocalAddresses()
+		if err != nil {
+			last = nil
+		}
+		var lastGW string
+		knownGW := false
+		if routes != nil {
+			gw, err := routes.DefaultGateway()
+			lastGW, knownGW = gw, err == nil
+		}
+
+		ticker := time.NewTicker(networkCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				current, err := probe.LocalAddresses()
+				if err != nil {
+					continue
+				}
+				if last != nil {
+					if changes := networkChanges(last, current); len(changes) > 0 {
+						s.annotate("", domain.AnnotationNetwork, "Rede local alterada: "+strings.Join(changes, "; "))
+						s.emit("network:change", map[string]interface{}{"changes": changes, "interfaces": current})
+					}
+				}
+				last = current
+
+				if routes == nil {
+					continue
+				}
+				if gw, err := routes.DefaultGateway(); err == nil {
+					if knownGW && gw != lastGW {
+						s.annotate("", domain.AnnotationNetwork, fmt.Sprintf("Gateway padrão alterado: %s → %s", routeLabel(lastGW), routeLabel(gw)))
+						s.emit("route:change", map[string]string{"previousGateway": lastGW, "gateway": gw})
+					}
+					lastGW, knownGW = gw, true
+				}
+			}
+		}
+	})
+}
+
+func routeLabel(gw string) string {
+	if gw == "" {
+		return "nenhum"
+	}
+	return gw
+}
+
+// networkChanges descreve as diferenças entre dois retratos das interfaces
+func networkChanges(before, after map[string][]string) []string {
+	names := map[string]bool{}
+	for n := range before {
+		names[n] = true
+	}
+	for n := range after {
+		names[n] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+
+	var out []string
+	for _, n := range sorted {
+		old, cur := strings.Join(before[n], ", "), strings.Join(after[n], ", ")
+		switch {
+		case old == cur:
+		case old == "":
+			out = append(out, fmt.Sprintf("%s conectada (%s)", n, cur))
+		case cur == "":
+			out = append(out, fmt.Sprintf("%s desconectada (era %s)", n, old))
+		default:
+			out = append(out, fmt.Sprintf("%s: %s → %s", n, old, cur))
+		}
+	}
+	return out
+}
+
+func formatAnnotations(list []domain.Annotation) string {
+	var b strings.Builder
+	b.WriteString("Anotações:\n")
+	for _, a := range list {
+		when := a.Start.Format("02/01 15:04")
+		if a.End != nil {
+			when += " até " + a.End.Format("02/01 15:04")
+		}
+		host := ""
+		if a.HostID != "" {
+			host = " (" + a.HostID + ")"
+		}
+		fmt.Fprintf(&b, "  - %s [%s]%s %s\n", when, annotationLabel(a.Kind), host, a.Text)
+	}
+	b.WriteString("------------------------------------------\n")
+	return b.String()
+}
+
+func annotationLabel(kind string) string {
+	switch kind {
+	case domain.AnnotationNetwork:
+		return "rede"
+	case domain.AnnotationConfig:
+		return "configuração"
+	}
+	return "nota"
+}
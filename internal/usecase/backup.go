@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"errors"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// Intervalo em que o agendador verifica se um backup automático está vencido
+const backupCheckInterval = time.Minute
+
+var errNoSnapshots = errors.New("o armazenamento atual não suporta backup")
+
+// SetBackupPolicy define os backups automáticos
+func (s *MonitorService) SetBackupPolicy(p domain.BackupPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backupPolicy = p
+}
+
+func (s *MonitorService) snapshotter() (domain.Snapshotter, error) {
+	snap, ok := s.repo.(domain.Snapshotter)
+	if !ok {
+		return nil, errNoSnapshots
+	}
+	return snap, nil
+}
+
+// StartBackupSchedule cria snapshots conforme a política até o serviço parar
+func (s *MonitorService) StartBackupSchedule() {
+	if _, err := s.snapshotter(); err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.goTracked(func() {
+		ticker := time.NewTicker(backupCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.backupIfDue()
+			}
+		}
+	})
+}
+
+// backupIfDue cria um snapshot se o mais recente for mais antigo que o intervalo
+func (s *MonitorService) backupIfDue() {
+	s.mu.RLock()
+	policy := s.backupPolicy
+	s.mu.RUnlock()
+	if !policy.Enabled {
+		return
+	}
+
+	interval := time.Duration(policy.IntervalHours) * time.Hour
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	if snaps, err := s.ListBackups(); err == nil && len(snaps) > 0 && time.Since(snaps[0].CreatedAt) < interval {
+		return
+	}
+	s.RunBackup()
+}
+
+// RunBackup cria um snapshot agora e aplica a rotação da política. Emite
+// "backup:created" ou "backup:failed".
+func (s *MonitorService) RunBackup() (domain.Snapshot, error) {
+	snap, err := s.snapshotter()
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+
+	created, err := snap.CreateSnapshot()
+	if err != nil {
+		s.emit("backup:failed", map[string]string{"error": err.Error()})
+		return created, err
+	}
+	s.emit("backup:created", created)
+
+	s.mu.RLock()
+	keep := s.backupPolicy.Keep
+	s.mu.RUnlock()
+	if keep > 0 {
+		if _, err := snap.RotateSnapshots(keep); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// ListBackups retorna os snapshots, do mais recente para o mais antigo
+func (s *MonitorService) ListBackups() ([]domain.Snapshot, error) {
+	snap, err := s.snapshotter()
+	if err != nil {
+		return nil, err
+	}
+	return snap.ListSnapshots()
+}
+
+// RestoreBackup agenda a restauração do snapshot para a próxima inicialização
+func (s *MonitorService) RestoreBackup(name string) error {
+	snap, err := s.snapshotter()
+	if err != nil {
+		return err
+	}
+	return snap.RestoreSnapshot(name)
+}
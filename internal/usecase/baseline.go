@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"lag-monitor/internal/domain"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// Peso de cada minuto novo na média móvel exponencial do slot
+	baselineAlpha = 0.05
+	// Minutos observados em um slot antes de usá-lo para detectar anomalias
+	baselineMinMinutes = 30
+	// Dias de histórico usados para treinar o baseline na inicialização
+	baselineTrainDays = 7
+	// Distância, em desvios-padrão, a partir da qual a janela é anômala
+	anomalySigma = 3.0
+
+	// Pisos de desvio: evitam alarmes em hosts muito estáveis
+	minLatencyDevMs = 2.0
+	minLossDevPct   = 2.0
+)
+
+// ewma mantém média e variância móveis exponenciais
+type ewma struct {
+	mean, variance float64
+	n              int
+}
+
+func (e *ewma) update(x float64) {
+	if e.n == 0 {
+		e.mean = x
+	} else {
+		diff := x - e.mean
+		e.mean += baselineAlpha * diff
+		e.variance = (1 - baselineAlpha) * (e.variance + baselineAlpha*diff*diff)
+	}
+	e.n++
+}
+
+func (e *ewma) std(floor float64) float64 {
+	return math.Max(math.Sqrt(e.variance), floor)
+}
+
+type baselineSlot struct {
+	latency ewma // ms
+	loss    ewma // %
+}
+
+// hostBaseline guarda os 168 slots (hora da semana) de um host e o minuto em acumulação
+type hostBaseline struct {
+	mu    sync.Mutex
+	slots [168]baselineSlot
+
+	minute time.Time
+	acc    statsAcc
+
+	anomalous map[string]bool // Métricas atualmente fora do normal (evita eventos repetidos)
+}
+
+func newHostBaseline() *hostBaseline {
+	return &hostBaseline{anomalous: make(map[string]bool)}
+}
+
+func hourOfWeek(t time.Time) int {
+	t = t.In(time.Local)
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// minuteWindow é o resumo de um minuto de amostras
+type minuteWindow struct {
+	start     time.Time
+	latencyMs float64
+	lossPct   float64
+	hasLat    bool
+}
+
+func (b *hostBaseline) closeMinute() (minuteWindow, bool) {
+	if b.acc.samples == 0 {
+		return minuteWindow{}, false
+	}
+
+	w := minuteWindow{start: b.minute}
+	var g domain.GroupStats
+	b.acc.fill(&g)
+	w.lossPct = g.LossPct
+	if b.acc.ok > 0 {
+		w.latencyMs = float64(g.AvgLatency) / 1000
+		w.hasLat = true
+	}
+	b.acc = statsAcc{}
+	return w, true
+}
+
+// learn incorpora o minuto ao slot correspondente
+func (b *hostBaseline) learn(w minuteWindow) {
+	slot := &b.slots[hourOfWeek(w.start)]
+	if w.hasLat {
+		slot.latency.update(w.latencyMs)
+	}
+	slot.loss.update(w.lossPct)
+}
+
+// check compara o minuto com o slot e retorna as métricas anômalas
+func (b *hostBaseline) check(hostID string, w minuteWindow) []domain.Anomaly {
+	slot := &b.slots[hourOfWeek(w.start)]
+	if slot.loss.n < baselineMinMinutes {
+		return nil
+	}
+
+	var out []domain.Anomaly
+	test := func(metric string, value float64, e *ewma, floor float64) {
+		std := e.std(floor)
+		score := (value - e.mean) / std
+		if score < anomalySigma {
+			return
+		}
+		out = append(out, domain.Anomaly{
+			HostID: hostID, Metric: metric, Value: value,
+			Expected: e.mean, StdDev: std, Score: score,
+			Window: w.start, Timestamp: time.Now(),
+		})
+	}
+
+	if w.hasLat && slot.latency.n >= baselineMinMinutes {
+		test("latency", w.latencyMs, &slot.latency, minLatencyDevMs)
+	}
+	test("loss", w.lossPct, &slot.loss, minLossDevPct)
+	return out
+}
+
+// feed acumula a amostra; ao virar o minuto, testa e aprende com o minuto anterior.
+// Retorna as anomalias que começaram neste minuto.
+func (b *hostBaseline) feed(hostID string, d domain.PingResult, live bool) []domain.Anomaly {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	m := d.Timestamp.Truncate(time.Minute)
+	var started []domain.Anomaly
+
+	if !m.Equal(b.minute) {
+		if w, ok := b.closeMinute(); ok {
+			if live {
+				found := make(map[string]bool)
+				for _, a := range b.check(hostID, w) {
+					found[a.Metric] = true
+					if !b.anomalous[a.Metric] {
+						started = append(started, a)
+					}
+				}
+				b.anomalous = found
+			}
+			// O minuto é testado antes de aprender para não absorver a própria anomalia
+			b.learn(w)
+		}
+		b.minute = m
+	}
+
+	b.acc.add(d)
+	return started
+}
+
+// observeBaseline alimenta o baseline do job com uma amostra ao vivo
+func (s *MonitorService) observeBaseline(job *monitorJob, res domain.PingResult) {
+	for _, a := range job.baseline.feed(job.host.ID, res, true) {
+		s.emit("anomaly:detected", a)
+	}
+}
+
+// trainBaseline aprende o comportamento do host a partir dos agregados por minuto
+// armazenados (o baseline trabalha em janelas de um minuto). Roda antes do runLoop do
+// job: os slots treinados substituem os vazios sem disputar com as amostras ao vivo.
+func (s *MonitorService) trainBaseline(job *monitorJob) {
+	end := time.Now()
+	rollups, err := s.repo.GetRollups(job.host.ID, domain.ResolutionMinute, end.AddDate(0, 0, -baselineTrainDays), end)
+	if err != nil {
+		return
+	}
+
+	trainer := newHostBaseline()
+	for _, r := range rollups {
+		if r.Count > 0 {
+			trainer.learn(windowOf(r))
+		}
+	}
+
+	job.baseline.mu.Lock()
+	job.baseline.slots = trainer.slots
+	job.baseline.mu.Unlock()
+}
+
+// GetBaseline retorna os slots aprendidos do host (apenas os já observados)
+func (s *MonitorService) GetBaseline(hostID string) []domain.BaselineSlot {
+	s.mu.RLock()
+	job, ok := s.targets[hostID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	job.baseline.mu.Lock()
+	defer job.baseline.mu.Unlock()
+
+	out := make([]domain.BaselineSlot, 0)
+	for i, slot := range job.baseline.slots {
+		if slot.loss.n == 0 {
+			continue
+		}
+		out = append(out, domain.BaselineSlot{
+			HourOfWeek: i,
+			LatencyMs:  slot.latency.mean,
+			LatencyDev: slot.latency.std(0),
+			LossPct:    slot.loss.mean,
+			LossDev:    slot.loss.std(0),
+			Minutes:    slot.loss.n,
+		})
+	}
+	return out
+}
+
+// baselineFor resume os slots que cobrem o período: latência esperada (ms) e desvio.
+// Retorna false se o baseline ainda não tem minutos suficientes.
+func (s *MonitorService) baselineFor(hostID string, start, end time.Time) (float64, float64, bool) {
+	s.mu.RLock()
+	job, ok := s.targets[hostID]
+	s.mu.RUnlock()
+	if !ok {
+		return 0, 0, false
+	}
+
+	job.baseline.mu.Lock()
+	defer job.baseline.mu.Unlock()
+
+	seen := make(map[int]bool)
+	var mean, variance float64
+	var n int
+	for t := start; !t.After(end) && len(seen) < 168; t = t.Add(time.Hour) {
+		h := hourOfWeek(t)
+		slot := job.baseline.slots[h]
+		if seen[h] || slot.latency.n < baselineMinMinutes {
+			continue
+		}
+		seen[h] = true
+		mean += slot.latency.mean
+		variance += slot.latency.variance
+		n++
+	}
+	if n == 0 {
+		return 0, 0, false
+	}
+	std := math.Max(math.Sqrt(variance/float64(n)), minLatencyDevMs)
+	return mean / float64(n), std, true
+}
+
+// windowOf converte um agregado de um minuto na janela usada pelo baseline
+func windowOf(r domain.Rollup) minuteWindow {
+	return minuteWindow{
+		start:     r.Bucket,
+		latencyMs: float64(r.LatAvg) / 1000,
+		lossPct:   float64(r.LossCount) / float64(r.Count) * 100,
+		hasLat:    r.Count > r.LossCount,
+	}
+}
+
+// countAnomalies conta os minutos (série de um minuto) que fogem do baseline,
+// ignorando os que caem nas janelas informadas
+func (s *MonitorService) countAnomalies(hostID string, minutes []domain.Rollup, skip []timeRange) int {
+	s.mu.RLock()
+	job, ok := s.targets[hostID]
+	s.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+
+	job.baseline.mu.Lock()
+	defer job.baseline.mu.Unlock()
+
+	count := 0
+	for _, m := range minutes {
+		if m.Count == 0 || overlapsRanges(skip, m.Bucket, time.Minute) {
+			continue
+		}
+		if len(job.baseline.check(hostID, windowOf(m))) > 0 {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Perda mínima de um intervalo para contar no cruzamento entre hosts (ignora pings
+// perdidos isolados)
+const comparisonLossMin = 0.02
+
+// BuildComparison calcula o relatório de cada host no mesmo período e cruza as perdas:
+// perdas simultâneas em vários destinos apontam para o trecho comum do caminho
+func (s *MonitorService) BuildComparison(hostIDs []string, start, end time.Time, opts ReportOptions) (domain.ComparisonData, error) {
+	cmp := domain.ComparisonData{Start: start, End: end, GeneratedAt: time.Now()}
+
+	seen := map[string]bool{}
+	for _, id := range hostIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		rep, err := s.BuildReport(id, start, end, opts)
+		if err != nil {
+			return cmp, fmt.Errorf("%s: %w", id, err)
+		}
+		cmp.Hosts = append(cmp.Hosts, rep)
+	}
+	if len(cmp.Hosts) < 2 {
+		return cmp, fmt.Errorf("selecione ao menos dois hosts para comparar")
+	}
+
+	cmp.Overlap, cmp.LossPeriods = correlateLosses(cmp.Hosts)
+	return cmp, nil
+}
+
+// correlateLosses cruza os intervalos com perda das séries dos hosts (todas com o
+// mesmo intervalo, ver GetHistorySeries)
+func correlateLosses(hosts []domain.ReportData) ([][]float64, []domain.LossPeriod) {
+	lossy := make([]map[int64]float64, len(hosts))
+	for i, h := range hosts {
+		lossy[i] = map[int64]float64{}
+		for _, pt := range h.Series.Points {
+			if pt.Count > 0 && pt.LossRatio >= comparisonLossMin {
+				lossy[i][pt.Time.Unix()] = pt.LossRatio
+			}
+		}
+	}
+
+	overlap := make([][]float64, len(hosts))
+	for i := range hosts {
+		overlap[i] = make([]float64, len(hosts))
+		if len(lossy[i]) == 0 {
+			continue
+		}
+		for j := range hosts {
+			both := 0
+			for ts := range lossy[i] {
+				if _, ok := lossy[j][ts]; ok {
+					both++
+				}
+			}
+			overlap[i][j] = float64(both) / float64(len(lossy[i])) * 100
+		}
+	}
+
+	// Intervalos em que dois ou mais hosts perderam, em ordem
+	seen := map[int64]bool{}
+	var times []int64
+	for _, l := range lossy {
+		for ts := range l {
+			if !seen[ts] {
+				seen[ts] = true
+				times = append(times, ts)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	bucket := hosts[0].Series.BucketSec
+	periods := []domain.LossPeriod{}
+	cur, curKey, last := -1, "", int64(0)
+	for _, ts := range times {
+		var ids []string
+		for i, l := range lossy {
+			if _, ok := l[ts]; ok {
+				ids = append(ids, hosts[i].HostID)
+			}
+		}
+		if len(ids) < 2 {
+			cur = -1
+			continue
+		}
+
+		// Intervalos seguidos com os mesmos hosts formam um único trecho
+		key := strings.Join(ids, "\x00")
+		if cur < 0 || key != curKey || ts != last+bucket {
+			periods = append(periods, domain.LossPeriod{
+				Start: time.Unix(ts, 0), HostIDs: ids, PeakPct: map[string]float64{},
+			})
+			cur, curKey = len(periods)-1, key
+		}
+		p := &periods[cur]
+		for i, l := range lossy {
+			if ratio, ok := l[ts]; ok {
+				p.PeakPct[hosts[i].HostID] = max(p.PeakPct[hosts[i].HostID], ratio*100)
+			}
+		}
+		p.End = time.Unix(ts+bucket, 0)
+		last = ts
+	}
+	return overlap, periods
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+const (
+	// Tolerância ao comparar amostras de hosts diferentes (os loops não são sincronizados)
+	correlationWindow = 2 * time.Second
+	// Quantidade de amostras recentes mantidas por job para a correlação ao vivo
+	recentSamples = 10
+)
+
+// chain retorna a cadeia de dependências do job: ele mesmo, o pai, ... até a raiz.
+// Ciclos e pais inexistentes interrompem a cadeia.
+func (s *MonitorService) chain(job *monitorJob) []*monitorJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := []*monitorJob{job}
+	seen := map[string]bool{job.host.ID: true}
+
+	for cur := job; cur.host.ParentID != ""; {
+		parent, ok := s.targets[cur.host.ParentID]
+		if !ok || seen[parent.host.ID] {
+			break
+		}
+		seen[parent.host.ID] = true
+		out = append(out, parent)
+		cur = parent
+	}
+	return out
+}
+
+// causeAt classifica o nó na posição i da cadeia em relação ao gateway:
+// depois do gateway é upstream, antes dele é LAN. Sem gateway, só a raiz é LAN.
+func causeAt(chain []*monitorJob, i int) domain.RootCause {
+	gw := -1
+	for idx, j := range chain {
+		if j.host.IsGW {
+			gw = idx
+			break
+		}
+	}
+
+	switch {
+	case gw < 0 && i == len(chain)-1:
+		return domain.CauseLAN
+	case gw < 0 || i < gw:
+		return domain.CauseUpstream
+	case i == gw:
+		return domain.CauseGateway
+	default:
+		return domain.CauseLAN
+	}
+}
+
+// classifyChain aponta o ancestral mais próximo da raiz que também perdeu pacotes
+// (ou o próprio host) e deriva a causa a partir da posição dele
+func classifyChain(chain []*monitorJob, lost func(i int) bool) (domain.RootCause, string) {
+	failed := 0
+	for i := len(chain) - 1; i >= 1; i-- {
+		if lost(i) {
+			failed = i
+			break
+		}
+	}
+	return causeAt(chain, failed), chain[failed].host.ID
+}
+
+// classifyLive classifica uma perda usando as amostras recentes dos ancestrais.
+// Retorna false se o host não declara dependências.
+func (s *MonitorService) classifyLive(job *monitorJob, ts time.Time) (domain.RootCauseEvent, bool) {
+	c := s.chain(job)
+	if len(c) < 2 {
+		return domain.RootCauseEvent{}, false
+	}
+
+	cause, failed := classifyChain(c, func(i int) bool { return c[i].lostNear(ts) })
+	return domain.RootCauseEvent{
+		HostID:     job.host.ID,
+		Cause:      cause,
+		FailedNode: failed,
+		Timestamp:  ts,
+	}, true
+}
+
+// classifyHistory conta as causas das perdas de um host no período, cruzando com o
+// histórico dos ancestrais. Retorna nil se o host não declara dependências.
+func (s *MonitorService) classifyHistory(hostID string, data []domain.PingResult, start, end time.Time) map[domain.RootCause]int {
+	s.mu.RLock()
+	job, ok := s.targets[hostID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	c := s.chain(job)
+	if len(c) < 2 {
+		return nil
+	}
+
+	losses := make([]map[int64]bool, len(c))
+	for i := 1; i < len(c); i++ {
+		losses[i] = s.lossSeconds(c[i], start, end)
+	}
+
+	causes := make(map[domain.RootCause]int)
+	for _, d := range data {
+		if !d.Loss {
+			continue
+		}
+		sec := d.Timestamp.Unix()
+		cause, _ := classifyChain(c, func(i int) bool { return nearLoss(losses[i], sec) })
+		causes[cause]++
+	}
+	return causes
+}
+
+// lossSeconds indexa, por segundo, as perdas registradas de um nó da cadeia
+func (s *MonitorService) lossSeconds(job *monitorJob, start, end time.Time) map[int64]bool {
+	data, err := s.repo.GetHistory(job.host.ID, start.Add(-correlationWindow), end.Add(correlationWindow))
+	if err != nil {
+		return nil
+	}
+
+	idx := make(map[int64]bool)
+	for _, d := range data {
+		if d.Loss {
+			idx[d.Timestamp.Unix()] = true
+		}
+	}
+	return idx
+}
+
+func nearLoss(idx map[int64]bool, sec int64) bool {
+	w := int64(correlationWindow / time.Second)
+	for t := sec - w; t <= sec+w; t++ {
+		if idx[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// record guarda a amostra no buffer circular do job
+func (j *monitorJob) record(res domain.PingResult) {
+	j.stateMu.Lock()
+	defer j.stateMu.Unlock()
+
+	if len(j.recent) >= recentSamples {
+		j.recent = j.recent[1:]
+	}
+	j.recent = append(j.recent, res)
+}
+
+// lostNear informa se o job perdeu algum pacote dentro da janela de correlação
+func (j *monitorJob) lostNear(ts time.Time) bool {
+	j.stateMu.Lock()
+	defer j.stateMu.Unlock()
+
+	for _, r := range j.recent {
+		diff := r.Timestamp.Sub(ts)
+		if r.Loss && diff <= correlationWindow && diff >= -correlationWindow {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-b * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q): esperado erro", expr)
+		}
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	// 2024-03-04 é uma segunda-feira
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.March, day, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"* * * * *", at(4, 13, 37), true},
+		{"30 2 * * *", at(4, 2, 30), true},
+		{"30 2 * * *", at(4, 2, 31), false},
+		{"*/15 * * * *", at(4, 10, 45), true},
+		{"*/15 * * * *", at(4, 10, 50), false},
+		{"0-30/10 * * * *", at(4, 10, 20), true},
+		{"0-30/10 * * * *", at(4, 10, 40), false},
+		{"5/20 * * * *", at(4, 10, 45), true},
+		{"5/20 * * * *", at(4, 10, 40), false},
+		{"0 8,18 * * *", at(4, 18, 0), true},
+		{"0 8,18 * * *", at(4, 12, 0), false},
+		{"0 9 * * 1-5", at(4, 9, 0), true},
+		{"0 9 * * 1-5", at(9, 9, 0), false}, // sábado
+		// Domingo pode ser 0 ou 7
+		{"0 9 * * 0", at(10, 9, 0), true},
+		{"0 9 * * 7", at(10, 9, 0), true},
+		{"0 0 1 * *", at(1, 0, 0), true},
+		{"0 0 1 * *", at(2, 0, 0), false},
+		{"0 0 * 4 *", at(4, 0, 0), false},
+		// Com dia do mês e da semana restritos, basta um casar
+		{"0 0 15 * 1", at(4, 0, 0), true},
+		{"0 0 15 * 1", at(15, 0, 0), true},
+		{"0 0 15 * 1", at(16, 0, 0), false},
+	}
+	for _, tt := range tests {
+		spec, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		if got := spec.matches(tt.t); got != tt.want {
+			t.Errorf("%q casa com %s = %v, esperado %v", tt.expr, tt.t.Format("Mon 2006-01-02 15:04"), got, tt.want)
+		}
+	}
+}
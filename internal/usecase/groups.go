@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statsAcc acumula amostras para compor estatísticas agregadas
+type statsAcc struct {
+	samples int
+	losses  int
+	ok      int
+	latSum  int64
+	jitSum  int64
+	minLat  int64
+	maxLat  int64
+}
+
+func (a *statsAcc) add(d domain.PingResult) {
+	a.samples++
+	if d.Loss {
+		a.losses++
+		return
+	}
+	if a.ok == 0 || d.Latency < a.minLat {
+		a.minLat = d.Latency
+	}
+	if d.Latency > a.maxLat {
+		a.maxLat = d.Latency
+	}
+	a.ok++
+	a.latSum += d.Latency
+	a.jitSum += d.Jitter
+}
+
+func (a *statsAcc) merge(o statsAcc) {
+	if o.ok > 0 {
+		if a.ok == 0 || o.minLat < a.minLat {
+			a.minLat = o.minLat
+		}
+		if o.maxLat > a.maxLat {
+			a.maxLat = o.maxLat
+		}
+	}
+	a.samples += o.samples
+	a.losses += o.losses
+	a.ok += o.ok
+	a.latSum += o.latSum
+	a.jitSum += o.jitSum
+}
+
+func (a *statsAcc) fill(g *domain.GroupStats) {
+	g.Samples = a.samples
+	g.MinLatency = a.minLat
+	g.MaxLatency = a.maxLat
+	if a.ok > 0 {
+		g.AvgLatency = a.latSum / int64(a.ok)
+		g.AvgJitter = a.jitSum / int64(a.ok)
+	}
+	if a.samples > 0 {
+		g.LossPct = float64(a.losses) / float64(a.samples) * 100
+	}
+}
+
+// SetHostTags substitui os grupos de um host
+func (s *MonitorService) SetHostTags(id string, tags []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, exists := s.targets[id]; exists {
+		job.host.Tags = domain.NormalizeTags(tags)
+	}
+}
+
+// groupMembers retorna os jobs de cada grupo
+func (s *MonitorService) groupMembers() map[string][]*monitorJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := make(map[string][]*monitorJob)
+	for _, job := range s.targets {
+		for _, tag := range job.host.Tags {
+			groups[tag] = append(groups[tag], job)
+		}
+	}
+	return groups
+}
+
+// GetGroups retorna as estatísticas ao vivo (amostras recentes) de cada grupo
+func (s *MonitorService) GetGroups() []domain.GroupStats {
+	result := make([]domain.GroupStats, 0)
+	for name, jobs := range s.groupMembers() {
+		g := domain.GroupStats{Group: name, Hosts: len(jobs)}
+		var acc statsAcc
+
+		for _, job := range jobs {
+			if job.hasIncident() {
+				g.Down++
+			}
+			for _, r := range job.recentSnapshot() {
+				acc.add(r)
+			}
+		}
+		acc.fill(&g)
+		result = append(result, g)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Group < result[j].Group })
+	return result
+}
+
+// groupHistory agrega o histórico do grupo. Além do total, retorna as estatísticas
+// de cada host (com Group preenchido com o ID do host).
+func (s *MonitorService) groupHistory(group string, start, end time.Time) (domain.GroupStats, []domain.GroupStats, error) {
+	jobs := s.groupMembers()[group]
+	if len(jobs) == 0 {
+		return domain.GroupStats{}, nil, fmt.Errorf("grupo %s não possui hosts", group)
+	}
+
+	total := domain.GroupStats{Group: group, Hosts: len(jobs)}
+	var totalAcc statsAcc
+	perHost := make([]domain.GroupStats, 0, len(jobs))
+
+	for _, job := range jobs {
+		acc, err := s.rangeStats(job.host.ID, start, end)
+		if err != nil {
+			return domain.GroupStats{}, nil, err
+		}
+
+		h := domain.GroupStats{Group: job.host.ID, Hosts: 1}
+		acc.fill(&h)
+		totalAcc.merge(acc)
+		perHost = append(perHost, h)
+	}
+	totalAcc.fill(&total)
+
+	sort.Slice(perHost, func(i, j int) bool { return perHost[i].Group < perHost[j].Group })
+	return total, perHost, nil
+}
+
+// GetGroupStats retorna as estatísticas históricas agregadas de um grupo
+func (s *MonitorService) GetGroupStats(group string, start, end time.Time) (domain.GroupStats, error) {
+	total, _, err := s.groupHistory(group, start, end)
+	return total, err
+}
+
+// GenerateGroupReport gera o resumo de um grupo com uma linha por host
+func (s *MonitorService) GenerateGroupReport(group string, start, end time.Time) (string, error) {
+	total, perHost, err := s.groupHistory(group, start, end)
+	if err != nil {
+		return "", err
+	}
+	if total.Samples == 0 {
+		return "", fmt.Errorf("sem dados no período")
+	}
+
+	var b strings.Builder
+	b.WriteString("=== RELATÓRIO DE GRUPO ===\n")
+	fmt.Fprintf(&b, "Grupo: %s (%d hosts)\n", group, total.Hosts)
+	fmt.Fprintf(&b, "Período: %s até %s\n", start.Format("02/01 15:04"), end.Format("02/01 15:04"))
+	b.WriteString("------------------------------------------\n")
+	fmt.Fprintf(&b, "Média de Atraso (Latência): %dms\n", total.AvgLatency/1000)
+	fmt.Fprintf(&b, "Jitter Médio: %dms\n", total.AvgJitter/1000)
+	fmt.Fprintf(&b, "Perda de Sinal: %.1f%%\n", total.LossPct)
+	b.WriteString("------------------------------------------\n")
+	b.WriteString("HOST;AMOSTRAS;MEDIA_MS;MIN_MS;MAX_MS;PERDA_%\n")
+	for _, h := range perHost {
+		fmt.Fprintf(&b, "%s;%d;%d;%d;%d;%.1f\n",
+			h.Group, h.Samples, h.AvgLatency/1000, h.MinLatency/1000, h.MaxLatency/1000, h.LossPct)
+	}
+
+	// Anotações globais e dos hosts do grupo
+	if list, err := s.ListAnnotations("", start, end); err == nil {
+		members := map[string]bool{}
+		for _, h := range perHost {
+			members[h.Group] = true
+		}
+		var relevant []domain.Annotation
+		for _, a := range list {
+			if a.HostID == "" || members[a.HostID] {
+				relevant = append(relevant, a)
+			}
+		}
+		if len(relevant) > 0 {
+			b.WriteString("------------------------------------------\n")
+			b.WriteString(formatAnnotations(relevant))
+		}
+	}
+	return b.String(), nil
+}
+
+// SetAlertRules substitui as regras de alerta por grupo
+func (s *MonitorService) SetAlertRules(rules []domain.AlertRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alertRules = rules
+}
+
+// StartAlertEvaluation avalia as regras de grupo periodicamente
+func (s *MonitorService) StartAlertEvaluation() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.goTracked(func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.evaluateAlerts()
+			}
+		}
+	})
+}
+
+// evaluateAlerts emite "alert:triggered" quando uma regra passa a ser violada e
+// "alert:resolved" quando volta ao normal
+func (s *MonitorService) evaluateAlerts() {
+	s.mu.RLock()
+	rules := s.alertRules
+	s.mu.RUnlock()
+
+	stats := make(map[string]domain.GroupStats)
+	for _, g := range s.GetGroups() {
+		stats[g.Group] = g
+	}
+
+	now := time.Now()
+	active := make(map[string]bool)
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		key := fmt.Sprintf("%+v", rule)
+
+		// Com o grupo inteiro em manutenção o alerta fica mudo e mantém o estado anterior
+		members, planned := s.groupMaintenance(rule.Group, now)
+		if len(members) > 0 && len(planned) == len(members) {
+			active[key] = s.firing[key]
+			continue
+		}
+		// Hosts em manutenção não contam como fora do ar
+		st := stats[rule.Group]
+		st.Down = 0
+		for _, job := range members {
+			if !planned[job] && job.hasIncident() {
+				st.Down++
+			}
+		}
+		reason := ruleViolation(rule, st)
+
+		alert := domain.GroupAlert{Rule: rule, Stats: st, Reason: reason, Timestamp: now}
+		switch {
+		case reason != "" && !s.firing[key]:
+			s.emit("alert:triggered", alert)
+		case reason == "" && s.firing[key]:
+			s.emit("alert:resolved", alert)
+		}
+		if reason != "" {
+			active[key] = true
+		}
+	}
+	s.firing = active
+}
+
+// ruleViolation descreve qual limite foi ultrapassado ("" se nenhum)
+func ruleViolation(rule domain.AlertRule, st domain.GroupStats) string {
+	var reasons []string
+	if rule.MaxLatencyMs > 0 && st.AvgLatency/1000 > rule.MaxLatencyMs {
+		reasons = append(reasons, fmt.Sprintf("latência média %dms > %dms", st.AvgLatency/1000, rule.MaxLatencyMs))
+	}
+	if rule.MaxLossPct > 0 && st.LossPct > rule.MaxLossPct {
+		reasons = append(reasons, fmt.Sprintf("perda %.1f%% > %.1f%%", st.LossPct, rule.MaxLossPct))
+	}
+	if rule.MinDown > 0 && st.Down >= rule.MinDown {
+		reasons = append(reasons, fmt.Sprintf("%d hosts fora do ar", st.Down))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func (j *monitorJob) recentSnapshot() []domain.PingResult {
+	j.stateMu.Lock()
+	defer j.stateMu.Unlock()
+
+	out := make([]domain.PingResult, len(j.recent))
+	copy(out, j.recent)
+	return out
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+// Start inicia as rotinas periódicas do serviço (retenção, alertas, backups e rede). O serviço para
+// sozinho quando ctx é cancelado.
+func (s *MonitorService) Start(ctx context.Context) {
+	s.StartRetentionPolicy()
+	s.StartAlertEvaluation()
+	s.StartBackupSchedule()
+	s.StartNetworkWatch()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			s.Stop(stopCtx)
+		case <-s.ctx.Done():
+		}
+	}()
+}
+
+// Stop cancela todos os jobs e rotinas e aguarda que terminem (ou ctx expirar).
+// Depois do Stop nenhuma amostra nova chega ao repositório, que pode ser fechado.
+func (s *MonitorService) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// goTracked executa fn em um goroutine acompanhado pelo Stop. Requer s.mu.
+func (s *MonitorService) goTracked(fn func()) bool {
+	if s.ctx.Err() != nil {
+		return false // Serviço já parado
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn()
+	}()
+	return true
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"lag-monitor/internal/domain"
+	"slices"
+	"time"
+)
+
+// timeRange é um intervalo [start, end)
+type timeRange struct {
+	start, end time.Time
+}
+
+func (r timeRange) contains(t time.Time) bool {
+	return !t.Before(r.start) && t.Before(r.end)
+}
+
+// maintenanceWindow é uma janela já validada, com a expressão cron compilada
+type maintenanceWindow struct {
+	domain.MaintenanceWindow
+	cron *cronSpec
+}
+
+// ValidateMaintenanceWindow verifica se a janela é única (start/end) ou recorrente (cron/duração)
+func ValidateMaintenanceWindow(w domain.MaintenanceWindow) error {
+	_, err := compileWindow(w)
+	return err
+}
+
+func compileWindow(w domain.MaintenanceWindow) (maintenanceWindow, error) {
+	if w.Cron != "" {
+		spec, err := parseCron(w.Cron)
+		if err != nil {
+			return maintenanceWindow{}, err
+		}
+		if w.DurationMin <= 0 {
+			return maintenanceWindow{}, fmt.Errorf("janela %q: duração deve ser maior que zero", w.Name)
+		}
+		return maintenanceWindow{MaintenanceWindow: w, cron: spec}, nil
+	}
+
+	if w.Start == nil || w.End == nil || !w.End.After(*w.Start) {
+		return maintenanceWindow{}, fmt.Errorf("janela %q: informe cron ou início e fim válidos", w.Name)
+	}
+	return maintenanceWindow{MaintenanceWindow: w}, nil
+}
+
+func (w maintenanceWindow) appliesTo(h domain.Host) bool {
+	if w.HostID == "" && w.Group == "" {
+		return true
+	}
+	if w.HostID != "" && w.HostID == h.ID {
+		return true
+	}
+	for _, tag := range h.Tags {
+		if w.Group != "" && tag == w.Group {
+			return true
+		}
+	}
+	return false
+}
+
+func (w maintenanceWindow) duration() time.Duration {
+	return time.Duration(w.DurationMin) * time.Minute
+}
+
+// activeAt informa se t está dentro de alguma ocorrência da janela
+func (w maintenanceWindow) activeAt(t time.Time) bool {
+	if w.cron == nil {
+		return timeRange{*w.Start, *w.End}.contains(t)
+	}
+
+	base := t.Truncate(time.Minute)
+	for m := 0; m < w.DurationMin; m++ {
+		if w.cron.matches(base.Add(-time.Duration(m) * time.Minute)) {
+			return true
+		}
+	}
+	return false
+}
+
+// intervals lista as ocorrências da janela que intersectam [from, to)
+func (w maintenanceWindow) intervals(from, to time.Time) []timeRange {
+	if w.cron == nil {
+		if w.End.After(from) && w.Start.Before(to) {
+			return []timeRange{{*w.Start, *w.End}}
+		}
+		return nil
+	}
+
+	var out []timeRange
+	for m := from.Add(-w.duration()).Truncate(time.Minute); m.Before(to); m = m.Add(time.Minute) {
+		if !w.cron.matches(m) {
+			continue
+		}
+		r := timeRange{m, m.Add(w.duration())}
+		// Une ocorrências sobrepostas (ex: cron a cada minuto com duração de 5)
+		if n := len(out); n > 0 && !r.start.After(out[n-1].end) {
+			out[n-1].end = r.end
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// SetMaintenanceWindows substitui as janelas de manutenção. Janelas inválidas são
+// ignoradas e reportadas no erro retornado.
+func (s *MonitorService) SetMaintenanceWindows(windows []domain.MaintenanceWindow) error {
+	var errs []error
+	compiled := make([]maintenanceWindow, 0, len(windows))
+	for _, w := range windows {
+		c, err := compileWindow(w)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	s.mu.Lock()
+	s.maintenance = compiled
+	s.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// inMaintenance informa se o host está em manutenção no instante t
+func (s *MonitorService) inMaintenance(h domain.Host, t time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, w := range s.maintenance {
+		if w.appliesTo(h) && w.activeAt(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupMaintenance retorna os hosts do grupo e, entre eles, os que estão em manutenção
+// no instante t, seja por janela global, do grupo ou do próprio host
+func (s *MonitorService) groupMaintenance(group string, t time.Time) ([]*monitorJob, map[*monitorJob]bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var members []*monitorJob
+	planned := make(map[*monitorJob]bool)
+	for _, job := range s.targets {
+		if !slices.Contains(job.host.Tags, group) {
+			continue
+		}
+		members = append(members, job)
+		for _, w := range s.maintenance {
+			if w.appliesTo(job.host) && w.activeAt(t) {
+				planned[job] = true
+				break
+			}
+		}
+	}
+	return members, planned
+}
+
+// excludeMaintenance remove as amostras que caem em janelas de manutenção do host
+func (s *MonitorService) excludeMaintenance(hostID string, data []domain.PingResult, start, end time.Time) ([]domain.PingResult, int) {
+	ranges := s.maintenanceRanges(hostID, start, end, ReportOptions{ExcludeMaintenance: true})
+	if len(ranges) == 0 {
+		return data, 0
+	}
+
+	kept := make([]domain.PingResult, 0, len(data))
+	for _, d := range data {
+		if !inRanges(ranges, d.Timestamp) {
+			kept = append(kept, d)
+		}
+	}
+	return kept, len(data) - len(kept)
+}
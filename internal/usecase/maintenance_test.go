@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"lag-monitor/internal/domain"
+)
+
+func TestGroupAlertIgnoresHostsInMaintenance(t *testing.T) {
+	now := time.Now()
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+	window := func(hostID, group string) domain.MaintenanceWindow {
+		return domain.MaintenanceWindow{Name: "teste", HostID: hostID, Group: group, Start: &start, End: &end}
+	}
+
+	tests := []struct {
+		name    string
+		windows []domain.MaintenanceWindow
+		want    []string
+	}{
+		{"sem manutenção", nil, []string{"alert:triggered"}},
+		// a e b estão fora do ar; com a em manutenção só b conta
+		{"um host em manutenção", []domain.MaintenanceWindow{window("a", "")}, nil},
+		{"host fora do ar ativo", []domain.MaintenanceWindow{window("c", "")}, []string{"alert:triggered"}},
+		{"todos os hosts em manutenção", []domain.MaintenanceWindow{window("a", ""), window("b", ""), window("c", "")}, nil},
+		{"janela do grupo", []domain.MaintenanceWindow{window("", "lan")}, nil},
+		{"janela de outro grupo", []domain.MaintenanceWindow{window("", "wan")}, []string{"alert:triggered"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			s := NewMonitorService(nil, nil, func(name string, data interface{}) {
+				events = append(events, name)
+			})
+			for _, id := range []string{"a", "b", "c"} {
+				job := &monitorJob{host: domain.Host{ID: id, Tags: []string{"lan"}}, active: true}
+				if id != "c" {
+					job.incident = &domain.Incident{HostID: id}
+				}
+				s.targets[id] = job
+			}
+			s.SetAlertRules([]domain.AlertRule{{Group: "lan", MinDown: 2, Enabled: true}})
+			if err := s.SetMaintenanceWindows(tt.windows); err != nil {
+				t.Fatal(err)
+			}
+
+			s.evaluateAlerts()
+			if !slices.Equal(events, tt.want) {
+				t.Errorf("eventos = %v, esperado %v", events, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlapsRanges(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2024, time.March, 4, h, m, 0, 0, time.UTC) }
+	// Janela das 04:00 às 04:15
+	window := []timeRange{{at(4, 0), at(4, 15)}}
+
+	tests := []struct {
+		name   string
+		ranges []timeRange
+		bucket time.Time
+		width  time.Duration
+		want   bool
+	}{
+		{"hora que contém a janela", window, at(4, 0), time.Hour, true},
+		{"hora que termina no início da janela", window, at(3, 0), time.Hour, false},
+		{"hora que alcança a janela", window, at(3, 30), time.Hour, true},
+		{"minuto dentro", window, at(4, 14), time.Minute, true},
+		{"minuto logo após", window, at(4, 15), time.Minute, false},
+		{"minuto logo antes", window, at(3, 59), time.Minute, false},
+		{"sem janelas", nil, at(4, 0), time.Hour, false},
+	}
+	for _, tt := range tests {
+		if got := overlapsRanges(tt.ranges, tt.bucket, tt.width); got != tt.want {
+			t.Errorf("%s: overlapsRanges = %v, esperado %v", tt.name, got, tt.want)
+		}
+	}
+}
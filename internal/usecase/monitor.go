@@ -5,10 +5,20 @@ import (
 	"fmt"
 	"lag-monitor/internal/domain"
 	"math"
+	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	// Intervalo entre pings de cada host
+	sampleInterval = 1 * time.Second
+	// Perdas consecutivas necessárias para abrir um incidente
+	incidentOpenAfter = 3
+	// Respostas consecutivas necessárias para encerrar um incidente
+	incidentCloseAfter = 3
+)
+
 // EventEmitter define a função de envio para o frontend
 type EventEmitter func(eventName string, data interface{})
 
@@ -18,6 +28,17 @@ type monitorJob struct {
 	cancel  context.CancelFunc
 	lastLat int64
 	active  bool // Estado local de execução
+
+	lossStreak int
+	okStreak   int
+
+	// Estado lido por outros jobs (correlação de causa raiz e supressão de alertas)
+	stateMu  sync.Mutex
+	incident *domain.Incident // Incidente aberto, se houver
+	opened   bool             // "incident:open" foi emitido para o incidente aberto
+	recent   []domain.PingResult
+
+	baseline *hostBaseline
 }
 
 // MonitorService gerencia os jobs
@@ -26,17 +47,43 @@ type MonitorService struct {
 	pinger domain.Pinger
 	emit   EventEmitter
 
-	mu      sync.RWMutex
-	targets map[string]*monitorJob
+	// Ciclo de vida: cancelado pelo Stop; wg acompanha jobs e rotinas periódicas
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu          sync.RWMutex
+	targets     map[string]*monitorJob
+	alertRules  []domain.AlertRule
+	firing      map[string]bool // Regras de grupo atualmente violadas
+	maintenance []maintenanceWindow
+	slaPolicy   domain.SLAPolicy
+	retention   domain.RetentionPolicy
+
+	cleanupMu   sync.Mutex // Uma execução da retenção por vez
+	lastCleanup *domain.CleanupReport
+
+	backupPolicy domain.BackupPolicy
+	netProbe     domain.NetworkProbe
+}
+
+// ReportOptions ajusta o cálculo dos relatórios
+type ReportOptions struct {
+	ExcludeMaintenance bool `json:"excludeMaintenance"` // Remove janelas de manutenção das médias e da perda
 }
 
 // NewMonitorService construtor
 func NewMonitorService(r domain.Repository, p domain.Pinger, e EventEmitter) *MonitorService {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &MonitorService{
-		repo:    r,
-		pinger:  p,
-		emit:    e,
-		targets: make(map[string]*monitorJob),
+		ctx:       ctx,
+		cancel:    cancel,
+		repo:      r,
+		pinger:    p,
+		emit:      e,
+		targets:   make(map[string]*monitorJob),
+		firing:    make(map[string]bool),
+		retention: domain.DefaultRetention,
 	}
 }
 
@@ -45,11 +92,12 @@ func (s *MonitorService) AddHost(h domain.Host) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.targets[h.ID]; exists {
+	if _, exists := s.targets[h.ID]; exists || s.ctx.Err() != nil {
 		return
 	}
+	h.Tags = domain.NormalizeTags(h.Tags)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(s.ctx)
 
 	// Define como ativo por padrão na criação se não especificado
 	activeState := true
@@ -64,14 +112,19 @@ func (s *MonitorService) AddHost(h domain.Host) {
 	h.Active = activeState
 
 	job := &monitorJob{
-		host:    h,
-		cancel:  cancel,
-		lastLat: 0,
-		active:  activeState,
+		host:     h,
+		cancel:   cancel,
+		lastLat:  0,
+		active:   activeState,
+		baseline: newHostBaseline(),
 	}
 	s.targets[h.ID] = job
 
-	go s.runLoop(ctx, job)
+	// O treino termina antes dos pings ao vivo alimentarem os mesmos slots
+	s.goTracked(func() {
+		s.trainBaseline(job)
+		s.runLoop(ctx, job)
+	})
 }
 
 // RemoveHost para o monitoramento e deleta
@@ -113,7 +166,7 @@ func (s *MonitorService) GetAllHosts() []domain.Host {
 
 // runLoop executa o ping periodicamente
 func (s *MonitorService) runLoop(ctx context.Context, job *monitorJob) {
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := time.NewTicker(sampleInterval)
 	defer ticker.Stop()
 
 	for {
@@ -153,123 +206,130 @@ func (s *MonitorService) runLoop(ctx context.Context, job *monitorJob) {
 			// Emite para o frontend e salva no banco
 			s.emit("ping:data", res)
 			s.repo.SaveBatch([]domain.PingResult{res})
+
+			job.record(res)
+			s.trackIncident(job, res)
+			s.observeBaseline(job, res)
 		}
 	}
 }
 
-func (s *MonitorService) GenerateReport(hostID string, start, end time.Time) (string, error) {
-	data, err := s.repo.GetHistory(hostID, start, end)
-	if err != nil {
-		return "", err
-	}
-
-	report := "RELATÓRIO DE LATÊNCIA - LAG MONITOR\n"
-	report += "Target: " + hostID + "\n"
-	report += "Período: " + start.Format("02/01/2006 15:04") + " até " + end.Format("02/01/2006 15:04") + "\n"
-	report += "--------------------------------------------------\n"
-
-	for _, d := range data {
-		report += d.Timestamp.Format("15:04:05") + " | Lat: " +
-			fmt.Sprintf("%vms", d.Latency/1000) + " | Jitter: " +
-			fmt.Sprintf("%vms", d.Jitter/1000) + "\n"
-	}
-
-	return report, nil
-}
+// trackIncident abre/fecha incidentes a partir de sequências de perda e emite
+// "incident:open" e "incident:close". Incidentes abertos enquanto um ancestral está
+// fora do ar são emitidos apenas como "incident:suppressed", e os abertos durante
+// uma janela de manutenção apenas como "incident:planned". Um incidente que já tinha
+// sido emitido como aberto e é suprimido depois (o pai caiu logo em seguida) ainda
+// recebe "incident:close", para fechar o par visto pelos hooks.
+func (s *MonitorService) trackIncident(job *monitorJob, res domain.PingResult) {
+	job.stateMu.Lock()
+	current := job.incident
+	job.stateMu.Unlock()
+
+	if res.Loss {
+		job.lossStreak++
+		job.okStreak = 0
+
+		if current != nil {
+			job.stateMu.Lock()
+			current.LostPackets++
+			job.stateMu.Unlock()
+			return
+		}
+		if job.lossStreak < incidentOpenAfter {
+			return
+		}
 
-func (s *MonitorService) GenerateDualReport(hostID string, start, end time.Time) (string, string, error) {
-	data, err := s.repo.GetHistory(hostID, start, end)
-	if err != nil {
-		return "", "", err
-	}
+		// O incidente começa no primeiro pacote perdido da sequência
+		start := res.Timestamp.Add(-time.Duration(job.lossStreak-1) * time.Second)
+		inc := &domain.Incident{
+			HostID:      job.host.ID,
+			IP:          job.host.IP,
+			Start:       start,
+			LostPackets: job.lossStreak,
+			Suppressed:  s.ancestorDown(job),
+			Planned:     s.inMaintenance(job.host, start),
+		}
+		ev, classified := s.classifyLive(job, res.Timestamp)
+		if classified {
+			inc.Cause = ev.Cause
+		}
 
-	if len(data) == 0 {
-		return "", "", fmt.Errorf("sem dados no período")
-	}
+		job.stateMu.Lock()
+		job.incident = inc
+		job.opened = !inc.Suppressed && !inc.Planned
+		job.stateMu.Unlock()
 
-	// --- CÁLCULOS PARA O RESUMO (LEIGO) ---
-	var totalLat int64
-	var maxLat int64
-	var minLat int64 = 999999
-	var lossCount int
+		// Filhos que abriram incidente antes deste host passam a ser suprimidos
+		s.suppressDescendants(job)
 
-	for _, d := range data {
-		if d.Loss {
-			lossCount++
-			continue
+		if inc.Suppressed {
+			s.emit("incident:suppressed", *inc)
+			return
 		}
-		latMs := d.Latency / 1000
-		totalLat += latMs
-		if latMs > maxLat {
-			maxLat = latMs
+		if inc.Planned {
+			s.emit("incident:planned", *inc)
+			return
 		}
-		if latMs < minLat {
-			minLat = latMs
+		if classified {
+			s.emit("rootcause:detected", ev)
 		}
+		s.emit("incident:open", *inc)
+		return
 	}
 
-	count := int64(len(data) - lossCount)
-	avgLat := int64(0)
-	if count > 0 {
-		avgLat = totalLat / count
-	}
-	lossPct := (float64(lossCount) / float64(len(data))) * 100
+	job.okStreak++
+	job.lossStreak = 0
 
-	// --- 1. RELATÓRIO AMIGÁVEL (RESUMO) ---
-	summary := fmt.Sprintf("=== RELATÓRIO DE QUALIDADE DE INTERNET ===\n")
-	summary += fmt.Sprintf("Destino: %s\n", hostID)
-	summary += fmt.Sprintf("Período: %s até %s\n", start.Format("02/01 15:04"), end.Format("02/01 15:04"))
-	summary += "------------------------------------------\n"
-	summary += fmt.Sprintf("Média de Atraso (Latência): %dms\n", avgLat)
+	if current != nil && job.okStreak >= incidentCloseAfter {
+		// O incidente termina na primeira resposta da sequência
+		end := res.Timestamp.Add(-time.Duration(job.okStreak-1) * time.Second)
 
-	status := "EXCELENTE"
-	if avgLat > 100 || lossPct > 2 {
-		status = "INSTÁVEL"
+		job.stateMu.Lock()
+		current.End = &end
+		closed := *current
+		opened := job.opened
+		job.incident, job.opened = nil, false
+		job.stateMu.Unlock()
+
+		if opened {
+			s.emit("incident:close", closed)
+		}
 	}
-	if avgLat > 200 || lossPct > 5 {
-		status = "CRÍTICO / RUIM"
+}
+
+func (s *MonitorService) GenerateReport(hostID string, start, end time.Time) (string, error) {
+	data, err := s.repo.GetHistory(hostID, start, end)
+	if err != nil {
+		return "", err
 	}
 
-	summary += fmt.Sprintf("Status da Conexão: %s\n", status)
-	summary += fmt.Sprintf("Perda de Sinal: %.1f%%\n", lossPct)
-	summary += "------------------------------------------\n"
-	summary += "DICA: Valores acima de 100ms ou perdas de sinal podem causar travamentos em vídeos e jogos.\n"
+	var b strings.Builder
+	b.WriteString("RELATÓRIO DE LATÊNCIA - LAG MONITOR\n")
+	b.WriteString("Target: " + hostID + "\n")
+	b.WriteString("Período: " + start.Format("02/01/2006 15:04") + " até " + end.Format("02/01/2006 15:04") + "\n")
+	b.WriteString("--------------------------------------------------\n")
 
-	// --- 2. DADOS BRUTOS (TÉCNICO) ---
-	raw := "TIMESTAMP;LATENCY_MS;JITTER_MS;LOSS\n"
 	for _, d := range data {
-		raw += fmt.Sprintf("%s;%d;%d;%v\n",
-			d.Timestamp.Format("2006-01-02 15:04:05"),
-			d.Latency/1000,
-			d.Jitter/1000,
-			d.Loss)
+		fmt.Fprintf(&b, "%s | Lat: %vms | Jitter: %vms\n", d.Timestamp.Format("15:04:05"), d.Latency/1000, d.Jitter/1000)
 	}
 
-	return summary, raw, nil
-}
-
-func (s *MonitorService) StartRetentionPolicy() {
-	ticker := time.NewTicker(1 * time.Hour)
-
-	go func() {
-		// O loop 'range' aguarda o canal enviar o sinal a cada 1 hora
-		for range ticker.C {
-			s.runCleanup()
-		}
-	}()
+	return b.String(), nil
 }
 
-// Extraído para uma função menor (Clean Code/Single Responsibility)
-func (s *MonitorService) runCleanup() {
-	daysStr, err := s.repo.GetSetting("retention_days")
-	if err != nil {
-		return
+// connectionStatus classifica a conexão. Com baseline aprendido, a latência é julgada
+// pelo normal do próprio host (um alvo transatlântico de 180ms não é "instável").
+func (s *MonitorService) connectionStatus(hostID string, start, end time.Time, avgLatMs int64, lossPct float64) string {
+	latWarn, latCrit := int64(100), int64(200)
+	if mean, std, ok := s.baselineFor(hostID, start, end); ok {
+		latWarn, latCrit = int64(mean+2*std), int64(mean+4*std)
 	}
 
-	var days int
-	if _, err := fmt.Sscanf(daysStr, "%d", &days); err != nil {
-		return
+	status := "EXCELENTE"
+	if avgLatMs > latWarn || lossPct > 2 {
+		status = "INSTÁVEL"
 	}
-
-	s.repo.CleanOldData(days)
+	if avgLatMs > latCrit || lossPct > 5 {
+		status = "CRÍTICO / RUIM"
+	}
+	return status
 }
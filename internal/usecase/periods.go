@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// Intervalos por período usados como amostras nos testes de latência e jitter
+const periodSamples = 200
+
+// ComparePeriods compara o host em dois períodos: os indicadores de cada um, a variação
+// e um teste de significância por indicador. Sem período base, usa o período de mesmo
+// tamanho imediatamente anterior (ex.: esta semana × a anterior).
+func (s *MonitorService) ComparePeriods(hostID string, baseStart, baseEnd, start, end time.Time, opts ReportOptions) (domain.PeriodComparison, error) {
+	if baseStart.IsZero() && baseEnd.IsZero() {
+		baseStart, baseEnd = start.Add(-end.Sub(start)), start
+	}
+	if !baseEnd.After(baseStart) || !end.After(start) {
+		return domain.PeriodComparison{}, fmt.Errorf("período inválido")
+	}
+
+	base, err := s.BuildReport(hostID, baseStart, baseEnd, opts)
+	if err != nil {
+		return domain.PeriodComparison{}, fmt.Errorf("período base: %w", err)
+	}
+	cur, err := s.BuildReport(hostID, start, end, opts)
+	if err != nil {
+		return domain.PeriodComparison{}, fmt.Errorf("período comparado: %w", err)
+	}
+
+	// O mesmo intervalo nos dois períodos deixa as amostras comparáveis
+	bucket := max((max(baseEnd.Sub(baseStart), end.Sub(start)) / periodSamples).Round(time.Minute),
+		base.Resolution.Duration(), cur.Resolution.Duration(), time.Minute)
+	baseRollups, err := s.periodRollups(hostID, baseStart, baseEnd, bucket, opts)
+	if err != nil {
+		return domain.PeriodComparison{}, err
+	}
+	curRollups, err := s.periodRollups(hostID, start, end, bucket, opts)
+	if err != nil {
+		return domain.PeriodComparison{}, err
+	}
+
+	cmp := domain.PeriodComparison{
+		HostID: hostID, HostName: cur.HostName, GeneratedAt: time.Now(),
+		Base: base, Current: cur, BucketSec: int64(bucket / time.Second),
+	}
+	for _, m := range []struct {
+		metric, label string
+		base, cur     int64
+		field         func(domain.Rollup) int64
+	}{
+		{"latAvg", "Latência média", base.Stats.LatAvg, cur.Stats.LatAvg, func(r domain.Rollup) int64 { return r.LatAvg }},
+		{"latP95", "Latência P95", base.Stats.LatP95, cur.Stats.LatP95, func(r domain.Rollup) int64 { return r.LatP95 }},
+		{"jitterAvg", "Jitter médio", base.Stats.JitterAvg, cur.Stats.JitterAvg, func(r domain.Rollup) int64 { return r.JitterAvg }},
+	} {
+		p := domain.MannWhitney(intervalValues(baseRollups, m.field), intervalValues(curRollups, m.field))
+		cmp.Deltas = append(cmp.Deltas, metricDelta(m.metric, m.label, "ms",
+			float64(m.base)/1000, float64(m.cur)/1000, "Mann-Whitney", p))
+	}
+
+	// Perdas vêm em rajadas: os pacotes não são independentes, mas os intervalos são
+	// comparáveis entre si
+	cmp.Deltas = append(cmp.Deltas, metricDelta("lossPct", "Perda de pacotes", "%",
+		base.Stats.LossPct, cur.Stats.LossPct, "Mann-Whitney",
+		domain.MannWhitney(intervalLoss(baseRollups), intervalLoss(curRollups))))
+
+	// Incidentes exigem amostras (brutas ou agregados por minuto) nos dois períodos
+	if base.SLA != nil && cur.SLA != nil {
+		k1, d1 := unplannedIncidents(base.Incidents), base.End.Sub(base.Start).Hours()/24
+		k2, d2 := unplannedIncidents(cur.Incidents), cur.End.Sub(cur.Start).Hours()/24
+		cmp.Deltas = append(cmp.Deltas, metricDelta("incidentsPerDay", "Incidentes por dia", "/dia",
+			float64(k1)/d1, float64(k2)/d2, "Poisson exato", domain.PoissonRates(k1, d1, k2, d2)))
+	}
+	return cmp, nil
+}
+
+// periodRollups agrega o período em intervalos de tamanho bucket, sem as janelas de
+// manutenção quando o relatório as exclui
+func (s *MonitorService) periodRollups(hostID string, start, end time.Time, bucket time.Duration, opts ReportOptions) ([]domain.Rollup, error) {
+	rollups, err := s.GetSeries(hostID, start, end, bucket)
+	if err != nil {
+		return nil, err
+	}
+	ranges := s.maintenanceRanges(hostID, start, end, opts)
+	if len(ranges) == 0 {
+		return rollups, nil
+	}
+	kept := rollups[:0]
+	for _, r := range rollups {
+		if !overlapsRanges(ranges, r.Bucket, bucket) {
+			kept = append(kept, r)
+		}
+	}
+	return kept, nil
+}
+
+// intervalValues extrai um indicador (em ms) dos intervalos com alguma resposta
+func intervalValues(rollups []domain.Rollup, field func(domain.Rollup) int64) []float64 {
+	var out []float64
+	for _, r := range rollups {
+		if r.Count > r.LossCount {
+			out = append(out, float64(field(r))/1000)
+		}
+	}
+	return out
+}
+
+// intervalLoss retorna a perda (%) de cada intervalo com pacotes enviados
+func intervalLoss(rollups []domain.Rollup) []float64 {
+	var out []float64
+	for _, r := range rollups {
+		if r.Count > 0 {
+			out = append(out, float64(r.LossCount)/float64(r.Count)*100)
+		}
+	}
+	return out
+}
+
+func unplannedIncidents(list []domain.Incident) int {
+	n := 0
+	for _, inc := range list {
+		if !inc.Planned {
+			n++
+		}
+	}
+	return n
+}
+
+func metricDelta(metric, label, unit string, base, cur float64, test string, p float64) domain.MetricDelta {
+	d := domain.MetricDelta{
+		Metric: metric, Label: label, Unit: unit,
+		Base: base, Current: cur, Delta: cur - base,
+		Test: test, PValue: p,
+	}
+	if base != 0 {
+		d.DeltaPct = d.Delta / base * 100
+	}
+	return d
+}
@@ -0,0 +1,463 @@
+package usecase
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"sort"
+	"time"
+)
+
+// Faixas do histograma do relatório; latências acima disso vão para uma última faixa
+const reportHistogramBins = 40
+
+// Amostras brutas carregadas de uma vez nos períodos longos
+const reportChunk = 24 * time.Hour
+
+// Máximo de amostras brutas anexadas ao relatório (~40 páginas no PDF); acima disso o
+// anexo traz os agregados da série e as amostras ficam para a exportação em CSV
+const reportAppendixLimit = 10000
+
+// BuildReport calcula os dados do relatório do host no período. Os indicadores vêm do
+// nível adequado ao período; SLA, incidentes e causas vêm das amostras brutas enquanto
+// existem e, antes disso, dos agregados por minuto.
+func (s *MonitorService) BuildReport(hostID string, start, end time.Time, opts ReportOptions) (domain.ReportData, error) {
+	rep := domain.ReportData{
+		HostID: hostID, Start: start, End: end, GeneratedAt: time.Now(),
+		Resolution:  s.pickResolution(start, end),
+		Incidents:   []domain.Incident{},
+		Annotations: []domain.Annotation{},
+	}
+	s.mu.RLock()
+	if job, ok := s.targets[hostID]; ok {
+		rep.HostName = job.host.Name
+	}
+	s.mu.RUnlock()
+
+	ranges := s.maintenanceRanges(hostID, start, end, opts)
+	var err error
+	if rep.Resolution == domain.ResolutionRaw {
+		err = s.fillRawReport(&rep, ranges)
+	} else {
+		err = s.fillRollupReport(&rep, ranges)
+	}
+	if err != nil {
+		return rep, err
+	}
+
+	rep.Status = s.connectionStatus(hostID, start, end, rep.Stats.LatAvg/1000, rep.Stats.LossPct)
+	if mean, std, ok := s.baselineFor(hostID, start, end); ok {
+		rep.NormalLatMs, rep.NormalStdMs = mean, std
+	}
+
+	if series, err := s.GetHistorySeries([]string{hostID}, start, end, 0); err == nil && len(series) > 0 {
+		rep.Series = series[0]
+	}
+	rep.Heatmap = s.reportHeatmap(hostID, start, end)
+	if list, err := s.ListAnnotations(hostID, start, end); err == nil {
+		rep.Annotations = list
+	}
+	return rep, nil
+}
+
+// ReportSamples retorna as amostras brutas do relatório para o anexo, inclusive as de
+// janelas de manutenção. Retorna nil quando o período já foi resumido pela retenção
+// ou passa de reportAppendixLimit amostras.
+func (s *MonitorService) ReportSamples(rep domain.ReportData) ([]domain.PingResult, error) {
+	if rep.Resolution != domain.ResolutionRaw || rep.Stats.Count+rep.PlannedSamples > reportAppendixLimit {
+		return nil, nil
+	}
+	return s.repo.GetHistory(rep.HostID, rep.Start, rep.End)
+}
+
+func (s *MonitorService) fillRawReport(rep *domain.ReportData, ranges []timeRange) error {
+	hostID, start, end := rep.HostID, rep.Start, rep.End
+
+	stats, err := s.repo.GetStats(hostID, domain.ResolutionRaw, start, end)
+	if err != nil {
+		return err
+	}
+	if stats.Count == 0 {
+		return fmt.Errorf("sem dados no período")
+	}
+	all, err := s.repo.GetHistory(hostID, start, end)
+	if err != nil {
+		return err
+	}
+
+	// Os incidentes ficam completos (marcados como previstos); os indicadores
+	// ignoram a manutenção quando pedido
+	data := all
+	if len(ranges) > 0 {
+		data, rep.PlannedSamples = s.excludeMaintenance(hostID, all, start, end)
+		if len(data) == 0 {
+			return fmt.Errorf("sem dados fora das janelas de manutenção")
+		}
+		if rep.PlannedSamples > 0 {
+			stats = domain.RangeStatsOf(hostID, data, start, end)
+		}
+	}
+	rep.Stats = stats
+
+	sla := computeSLA(data, s.currentSLAPolicy())
+	sla.HostID, sla.Start, sla.End = hostID, start, end
+	rep.SLA = &sla
+
+	if stats.LossCount > 0 {
+		rep.Causes = s.classifyHistory(hostID, data, start, end)
+	}
+	if minutes, err := s.repo.GetSeries(hostID, domain.ResolutionRaw, start, end, time.Minute); err == nil {
+		rep.AnomalyMinutes = s.countAnomalies(hostID, minutes, ranges)
+	}
+
+	planned := s.maintenanceRanges(hostID, start, end, ReportOptions{ExcludeMaintenance: true})
+	rep.Incidents = incidentsFrom(hostID, all, planned)
+	rep.Histogram = reportHistogram(data, stats.LatP99)
+	return nil
+}
+
+// fillRollupReport monta os indicadores a partir dos agregados. Com a manutenção
+// excluída, sai todo intervalo que toca uma janela: no nível de hora, uma janela das
+// 04:00 às 04:15 remove a hora inteira, e as amostras fora dela contam como previstas.
+func (s *MonitorService) fillRollupReport(rep *domain.ReportData, ranges []timeRange) error {
+	rollups, err := s.repo.GetRollups(rep.HostID, rep.Resolution, rep.Start, rep.End)
+	if err != nil {
+		return err
+	}
+	if len(rollups) == 0 {
+		return fmt.Errorf("sem dados no período")
+	}
+
+	kept := rollups[:0:0]
+	for _, r := range rollups {
+		if overlapsRanges(ranges, r.Bucket, rep.Resolution.Duration()) {
+			rep.PlannedSamples += r.Count
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if len(kept) == 0 {
+		return fmt.Errorf("sem dados fora das janelas de manutenção")
+	}
+
+	merged := domain.MergeRollups(rep.HostID, rep.Resolution, kept, rep.Start, 0)
+	rep.Stats = domain.StatsFromRollup(merged[0], rep.End)
+
+	scan, err := s.scanPeriod(rep.HostID, rep.Start, rep.End, ranges, s.currentSLAPolicy(), true)
+	if err != nil {
+		return err
+	}
+	if scan.sla.samples > 0 {
+		sla := scan.sla.report()
+		sla.HostID, sla.Start, sla.End = rep.HostID, rep.Start, rep.End
+		rep.SLA = &sla
+	}
+	rep.Incidents = scan.incidents.list()
+	if len(scan.causes) > 0 {
+		rep.Causes = scan.causes
+	}
+	rep.AnomalyMinutes = s.countAnomalies(rep.HostID, scan.minutes, ranges)
+	if scan.rawOnly {
+		rep.Histogram = foldHistogram(scan.histogram(), histogramStep(rep.Stats.LatP99))
+	}
+	return nil
+}
+
+// periodScan reúne o que os indicadores agregados não dão: SLA, incidentes, causas das
+// perdas, a série por minuto (anomalias) e as latências para o histograma
+type periodScan struct {
+	sla       *slaAcc
+	incidents *incidentAcc
+	causes    map[domain.RootCause]int
+	minutes   []domain.Rollup
+	latencies map[int64]int // Contagem por faixa de histogramBase
+	rawOnly   bool          // Todo o período veio das amostras brutas
+}
+
+// Faixa fina em que o histograma é acumulado; as faixas do relatório são múltiplas dela
+const histogramBase = 1000
+
+// scanPeriod percorre o período sem carregá-lo de uma vez: as amostras brutas em blocos
+// de reportChunk e, antes do início da retenção delas, os agregados por minuto. Amostras
+// nas janelas em ranges ficam fora do SLA e das causas, mas os incidentes são todos
+// listados (os de manutenção marcados como previstos). details inclui causas, série por
+// minuto e histograma.
+func (s *MonitorService) scanPeriod(hostID string, start, end time.Time, ranges []timeRange, policy domain.SLAPolicy, details bool) (*periodScan, error) {
+	planned := s.maintenanceRanges(hostID, start, end, ReportOptions{ExcludeMaintenance: true})
+	scan := &periodScan{
+		sla:       newSLAAcc(policy),
+		incidents: &incidentAcc{hostID: hostID, planned: planned},
+		causes:    map[domain.RootCause]int{},
+		latencies: map[int64]int{},
+	}
+
+	// Antes de split só restam os agregados por minuto
+	split := s.rawSince(hostID)
+	if split.Before(start) {
+		split = start
+	}
+	if split.After(end) {
+		split = end
+	}
+	scan.rawOnly = !split.After(start)
+
+	if split.After(start) {
+		minutes, err := s.repo.GetRollups(hostID, domain.ResolutionMinute, start, split.Add(-time.Nanosecond))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range minutes {
+			if r.Count == 0 {
+				continue
+			}
+			scan.incidents.addMinute(r)
+			if overlapsRanges(ranges, r.Bucket, time.Minute) {
+				continue
+			}
+			scan.sla.addMinute(r)
+			if details && r.LossCount > 0 {
+				scan.causes[domain.CauseUnknown] += r.LossCount
+			}
+		}
+		scan.minutes = minutes
+	}
+
+	for from := split; from.Before(end); {
+		to := from.Add(reportChunk)
+		last := !to.Before(end)
+		if last {
+			to = end
+		}
+		// Os blocos não se sobrepõem; só o último inclui o fim do período
+		until := to
+		if !last {
+			until = to.Add(-time.Nanosecond)
+		}
+
+		data, err := s.repo.GetHistory(hostID, from, until)
+		if err != nil {
+			return nil, err
+		}
+		kept := data
+		if len(ranges) > 0 {
+			kept = make([]domain.PingResult, 0, len(data))
+		}
+		lost := false
+		for _, d := range data {
+			scan.incidents.add(d)
+			if len(ranges) > 0 {
+				if inRanges(ranges, d.Timestamp) {
+					continue
+				}
+				kept = append(kept, d)
+			}
+			scan.sla.add(d)
+			lost = lost || d.Loss
+		}
+		if details {
+			if lost {
+				for cause, n := range s.classifyHistory(hostID, kept, from, until) {
+					scan.causes[cause] += n
+				}
+			}
+			for _, d := range kept {
+				if !d.Loss {
+					scan.latencies[d.Latency/histogramBase]++
+				}
+			}
+		}
+		if last {
+			break
+		}
+		from = to
+	}
+
+	if details && split.Before(end) {
+		if series, err := s.repo.GetSeries(hostID, domain.ResolutionRaw, split, end, time.Minute); err == nil {
+			scan.minutes = append(scan.minutes, series...)
+		}
+	}
+	return scan, nil
+}
+
+// histogram devolve as latências acumuladas em faixas de histogramBase
+func (p *periodScan) histogram() []domain.HistogramBin {
+	out := make([]domain.HistogramBin, 0, len(p.latencies))
+	for bin, n := range p.latencies {
+		out = append(out, domain.HistogramBin{From: bin * histogramBase, To: (bin + 1) * histogramBase, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].From < out[j].From })
+	return out
+}
+
+// rawSince retorna desde quando as amostras brutas do host ainda estão na retenção
+func (s *MonitorService) rawSince(hostID string) time.Time {
+	n := s.currentRetention().RawDays
+	s.mu.RLock()
+	if job, ok := s.targets[hostID]; ok && job.host.RetentionDays > 0 {
+		n = job.host.RetentionDays
+	}
+	s.mu.RUnlock()
+	return time.Now().Add(-days(n))
+}
+
+// incidentAcc reconstrói os incidentes: sequências de perdas longas o bastante para
+// abrir um incidente ao vivo ou, pelos agregados, minutos seguidos de queda
+type incidentAcc struct {
+	hostID  string
+	planned []timeRange
+	out     []domain.Incident
+
+	first, last time.Time
+	run         int
+
+	lastMinute time.Time
+	minuteOpen bool // O último incidente veio de um minuto agregado e pode continuar
+}
+
+func (a *incidentAcc) add(d domain.PingResult) {
+	a.minuteOpen = false
+	if !d.Loss {
+		a.closeRun()
+		return
+	}
+	if a.run == 0 {
+		a.first = d.Timestamp
+	}
+	a.last = d.Timestamp
+	a.run++
+}
+
+func (a *incidentAcc) closeRun() {
+	if a.run >= incidentOpenAfter {
+		end := a.last.Add(sampleInterval)
+		a.out = append(a.out, domain.Incident{
+			HostID: a.hostID, Start: a.first, End: &end, LostPackets: a.run,
+			Planned: inRanges(a.planned, a.first),
+		})
+	}
+	a.run = 0
+}
+
+func (a *incidentAcc) addMinute(r domain.Rollup) {
+	a.closeRun()
+	follows := a.lastMinute.Add(time.Minute).Equal(r.Bucket)
+	a.lastMinute = r.Bucket
+	if !outageMinute(r) {
+		a.minuteOpen = false
+		return
+	}
+
+	end := r.Bucket.Add(time.Minute)
+	if a.minuteOpen && follows {
+		inc := &a.out[len(a.out)-1]
+		inc.End = &end
+		inc.LostPackets += r.LossCount
+		return
+	}
+	a.out = append(a.out, domain.Incident{
+		HostID: a.hostID, Start: r.Bucket, End: &end, LostPackets: r.LossCount,
+		Planned: overlapsRanges(a.planned, r.Bucket, time.Minute),
+	})
+	a.minuteOpen = true
+}
+
+func (a *incidentAcc) list() []domain.Incident {
+	a.closeRun()
+	if a.out == nil {
+		return []domain.Incident{}
+	}
+	return a.out
+}
+
+// incidentsFrom reconstrói os incidentes das amostras do período
+func incidentsFrom(hostID string, data []domain.PingResult, planned []timeRange) []domain.Incident {
+	acc := incidentAcc{hostID: hostID, planned: planned}
+	for _, d := range data {
+		acc.add(d)
+	}
+	return acc.list()
+}
+
+// reportHistogram distribui as latências nas faixas do relatório (ver histogramStep)
+func reportHistogram(data []domain.PingResult, p99 int64) []domain.HistogramBin {
+	step := histogramStep(p99)
+	return foldHistogram(domain.Histogram(data, step), step)
+}
+
+// histogramStep escolhe uma faixa "redonda" que cubra até o P99 em no máximo
+// reportHistogramBins faixas
+func histogramStep(p99 int64) int64 {
+	var step int64
+	for _, ms := range []int64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000} {
+		step = ms * 1000
+		if p99/step < reportHistogramBins {
+			break
+		}
+	}
+	return step
+}
+
+// foldHistogram junta faixas finas (múltiplas de step) nas faixas de step; acima de
+// reportHistogramBins faixas o restante é somado em uma última faixa
+func foldHistogram(bins []domain.HistogramBin, step int64) []domain.HistogramBin {
+	var merged []domain.HistogramBin
+	for _, b := range bins {
+		from := b.From / step * step
+		if n := len(merged); n > 0 && merged[n-1].From == from {
+			merged[n-1].Count += b.Count
+			continue
+		}
+		merged = append(merged, domain.HistogramBin{From: from, To: from + step, Count: b.Count})
+	}
+
+	limit := step * reportHistogramBins
+	out := []domain.HistogramBin{}
+	for _, b := range merged {
+		if b.From < limit {
+			out = append(out, b)
+			continue
+		}
+		if last := len(out) - 1; last >= 0 && out[last].From == limit {
+			out[last].To, out[last].Count = b.To, out[last].Count+b.Count
+			continue
+		}
+		out = append(out, domain.HistogramBin{From: limit, To: b.To, Count: b.Count})
+	}
+	return out
+}
+
+// reportHeatmap resume o período por dia da semana e hora (fuso local)
+func (s *MonitorService) reportHeatmap(hostID string, start, end time.Time) []domain.HeatCell {
+	type acc struct {
+		count, loss, ok int
+		latSum          int64
+	}
+	var cells [7][24]acc
+
+	if rollups, err := s.GetSeries(hostID, start, end, time.Hour); err == nil {
+		for _, r := range rollups {
+			t := r.Bucket.Local()
+			c := &cells[t.Weekday()][t.Hour()]
+			ok := r.Count - r.LossCount
+			c.count += r.Count
+			c.loss += r.LossCount
+			c.ok += ok
+			c.latSum += r.LatAvg * int64(ok)
+		}
+	}
+
+	out := make([]domain.HeatCell, 0, 7*24)
+	for wd := range cells {
+		for h, c := range cells[wd] {
+			cell := domain.HeatCell{Weekday: time.Weekday(wd), Hour: h, Count: c.count}
+			if c.ok > 0 {
+				cell.LatAvg = c.latSum / int64(c.ok)
+			}
+			if c.count > 0 {
+				cell.LossPct = float64(c.loss) / float64(c.count) * 100
+			}
+			out = append(out, cell)
+		}
+	}
+	return out
+}
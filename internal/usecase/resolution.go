@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+const (
+	// Até este tamanho, consultas usam as amostras brutas
+	maxRawRange = 48 * time.Hour
+	// Até este tamanho, consultas usam os agregados por minuto
+	maxMinuteRange = 60 * 24 * time.Hour
+)
+
+// SetRetention define por quanto tempo cada nível é mantido (zeros usam o padrão)
+func (s *MonitorService) SetRetention(p domain.RetentionPolicy) {
+	if p.RawDays <= 0 {
+		p.RawDays = domain.DefaultRetention.RawDays
+	}
+	if p.MinuteDays <= 0 {
+		p.MinuteDays = domain.DefaultRetention.MinuteDays
+	}
+	if p.HourDays <= 0 {
+		p.HourDays = domain.DefaultRetention.HourDays
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retention = p
+}
+
+func (s *MonitorService) currentRetention() domain.RetentionPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.retention
+}
+
+// pickResolution escolhe o nível mais fino que cobre o período sem carregar
+// milhões de linhas e que ainda existe dentro da retenção
+func (s *MonitorService) pickResolution(start, end time.Time) domain.Resolution {
+	policy := s.currentRetention()
+	age := time.Since(start)
+	span := end.Sub(start)
+
+	switch {
+	case span <= maxRawRange && age <= days(policy.RawDays):
+		return domain.ResolutionRaw
+	case span <= maxMinuteRange && age <= days(policy.MinuteDays):
+		return domain.ResolutionMinute
+	default:
+		return domain.ResolutionHour
+	}
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+// addRollup incorpora um agregado ao acumulador
+func (a *statsAcc) addRollup(r domain.Rollup) {
+	ok := r.Count - r.LossCount
+	a.samples += r.Count
+	a.losses += r.LossCount
+	if ok == 0 {
+		return
+	}
+	if a.ok == 0 || r.LatMin < a.minLat {
+		a.minLat = r.LatMin
+	}
+	if r.LatMax > a.maxLat {
+		a.maxLat = r.LatMax
+	}
+	a.ok += ok
+	a.latSum += r.LatAvg * int64(ok)
+	a.jitSum += r.JitterAvg * int64(ok)
+}
+
+// rangeStats agrega o período de um host no nível adequado ao tamanho do período
+func (s *MonitorService) rangeStats(hostID string, start, end time.Time) (statsAcc, error) {
+	st, err := s.GetStats(hostID, start, end)
+	if err != nil {
+		return statsAcc{}, err
+	}
+	return accFromStats(st), nil
+}
+
+// maintenanceRanges retorna as janelas do host no período, se o relatório deve excluí-las
+func (s *MonitorService) maintenanceRanges(hostID string, start, end time.Time, opts ReportOptions) []timeRange {
+	if !opts.ExcludeMaintenance {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	host := domain.Host{ID: hostID}
+	if job, ok := s.targets[hostID]; ok {
+		host = job.host
+	}
+
+	var ranges []timeRange
+	for _, w := range s.maintenance {
+		if w.appliesTo(host) {
+			ranges = append(ranges, w.intervals(start, end)...)
+		}
+	}
+	return ranges
+}
+
+func inRanges(ranges []timeRange, t time.Time) bool {
+	for _, r := range ranges {
+		if r.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsRanges informa se o intervalo [start, start+width) toca alguma janela. Um
+// agregado não diz quando cada amostra caiu, então o que toca a janela sai inteiro.
+func overlapsRanges(ranges []timeRange, start time.Time, width time.Duration) bool {
+	end := start.Add(width)
+	for _, r := range ranges {
+		if r.start.Before(end) && r.end.After(start) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"errors"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// Intervalo entre execuções automáticas da retenção
+const cleanupInterval = 1 * time.Hour
+
+// StartRetentionPolicy aplica a retenção imediatamente e depois a cada hora
+func (s *MonitorService) StartRetentionPolicy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.goTracked(func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			s.RunCleanup()
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// TriggerCleanup aplica a retenção em segundo plano (ex: após mudar a configuração)
+func (s *MonitorService) TriggerCleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.goTracked(func() { s.RunCleanup() })
+}
+
+// RunCleanup remove os dados fora da retenção de cada nível, devolve o espaço ao
+// sistema e emite "retention:cleanup" com o resultado
+func (s *MonitorService) RunCleanup() (domain.CleanupReport, error) {
+	s.cleanupMu.Lock()
+	defer s.cleanupMu.Unlock()
+
+	started := time.Now()
+	policy := s.currentRetention()
+	report := domain.CleanupReport{Timestamp: started}
+
+	var errs []error
+	var err error
+	if report.RawDeleted, err = s.repo.CleanOldData(policy.RawDays, s.retentionOverrides()); err != nil {
+		errs = append(errs, err)
+	}
+	if report.MinuteDeleted, err = s.repo.CleanOldRollups(domain.ResolutionMinute, policy.MinuteDays); err != nil {
+		errs = append(errs, err)
+	}
+	if report.HourDeleted, err = s.repo.CleanOldRollups(domain.ResolutionHour, policy.HourDays); err != nil {
+		errs = append(errs, err)
+	}
+	if report.BytesReclaimed, err = s.repo.Compact(); err != nil {
+		errs = append(errs, err)
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		report.Error = err.Error()
+	}
+	report.DurationMs = time.Since(started).Milliseconds()
+
+	s.mu.Lock()
+	s.lastCleanup = &report
+	s.mu.Unlock()
+
+	s.emit("retention:cleanup", report)
+	return report, err
+}
+
+// LastCleanup retorna o resultado da última execução (nil se ainda não rodou)
+func (s *MonitorService) LastCleanup() *domain.CleanupReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastCleanup
+}
+
+// SetHostRetention define por quantos dias as amostras brutas do host são mantidas
+// (0 volta a usar a retenção global)
+func (s *MonitorService) SetHostRetention(id string, days int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, exists := s.targets[id]; exists {
+		job.host.RetentionDays = max(days, 0)
+	}
+}
+
+// retentionOverrides retorna os hosts com retenção própria
+func (s *MonitorService) retentionOverrides() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	overrides := make(map[string]int)
+	for id, job := range s.targets {
+		if job.host.RetentionDays > 0 {
+			overrides[id] = job.host.RetentionDays
+		}
+	}
+	return overrides
+}
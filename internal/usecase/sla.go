@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// SetSLAPolicy define a política usada nos relatórios e em GetSLA
+func (s *MonitorService) SetSLAPolicy(p domain.SLAPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.slaPolicy = p
+}
+
+// ValidateSLAPolicy verifica a definição de disponibilidade, o limite de latência e a
+// meta. Definição vazia vale como SLALoss.
+func ValidateSLAPolicy(p domain.SLAPolicy) error {
+	switch p.Definition {
+	case "", domain.SLALoss, domain.SLAIncident, domain.SLALatency:
+	default:
+		return fmt.Errorf("definição de SLA %q desconhecida (use %s, %s ou %s)",
+			p.Definition, domain.SLALoss, domain.SLAIncident, domain.SLALatency)
+	}
+	if p.LatencyThresholdMs < 0 {
+		return fmt.Errorf("limite de latência do SLA negativo: %dms", p.LatencyThresholdMs)
+	}
+	if p.TargetPct < 0 || p.TargetPct > 100 {
+		return fmt.Errorf("meta de SLA fora de 0 a 100: %v%%", p.TargetPct)
+	}
+	return nil
+}
+
+func (s *MonitorService) currentSLAPolicy() domain.SLAPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.slaPolicy
+}
+
+// GetSLA calcula a disponibilidade do host no período. Uma política vazia usa a configurada.
+// As amostras brutas são lidas em blocos; onde já saíram da retenção, o SLA é estimado
+// pelos agregados por minuto.
+func (s *MonitorService) GetSLA(hostID string, start, end time.Time, policy domain.SLAPolicy, opts ReportOptions) (domain.SLAReport, error) {
+	if policy.Definition == "" {
+		policy = s.currentSLAPolicy()
+	}
+	if err := ValidateSLAPolicy(policy); err != nil {
+		return domain.SLAReport{}, err
+	}
+
+	scan, err := s.scanPeriod(hostID, start, end, s.maintenanceRanges(hostID, start, end, opts), policy, false)
+	if err != nil {
+		return domain.SLAReport{}, err
+	}
+	if scan.sla.samples == 0 {
+		return domain.SLAReport{}, fmt.Errorf("sem dados no período")
+	}
+
+	rep := scan.sla.report()
+	rep.HostID, rep.Start, rep.End = hostID, start, end
+	return rep, nil
+}
+
+// computeSLA calcula disponibilidade, quedas, MTBF e MTTR. Cada amostra representa um
+// intervalo de ping; períodos sem amostras (app fechado) não entram na conta.
+func computeSLA(data []domain.PingResult, policy domain.SLAPolicy) domain.SLAReport {
+	acc := newSLAAcc(policy)
+	for _, d := range data {
+		acc.add(d)
+	}
+	return acc.report()
+}
+
+// slaAcc acumula a disponibilidade amostra a amostra e, onde as amostras brutas já
+// saíram da retenção, minuto a minuto pelos agregados
+type slaAcc struct {
+	policy domain.SLAPolicy
+
+	samples, bad           int
+	outages, outageSamples int
+	run                    int // Amostras ruins seguidas
+
+	lastMinute   time.Time
+	minuteOutage bool // O último minuto agregado foi de queda
+	fromRollups  bool
+}
+
+func newSLAAcc(policy domain.SLAPolicy) *slaAcc {
+	if policy.Definition == "" {
+		policy.Definition = domain.SLALoss
+	}
+	return &slaAcc{policy: policy}
+}
+
+func (a *slaAcc) slow(latencyUs int64) bool {
+	return a.policy.Definition == domain.SLALatency && a.policy.LatencyThresholdMs > 0 &&
+		latencyUs/1000 > a.policy.LatencyThresholdMs
+}
+
+func (a *slaAcc) add(d domain.PingResult) {
+	a.minuteOutage = false
+	a.samples++
+	if d.Loss || a.slow(d.Latency) {
+		a.bad++
+		a.run++
+		return
+	}
+	a.closeRun()
+}
+
+// Quedas são sequências de amostras ruins longas o bastante para abrir um incidente
+func (a *slaAcc) closeRun() {
+	if a.run >= incidentOpenAfter {
+		a.outages++
+		a.outageSamples += a.run
+	}
+	a.run = 0
+}
+
+// addMinute usa o agregado de um minuto: minutos seguidos que perderam a maior parte dos
+// pacotes formam uma queda (ver outageMinute)
+func (a *slaAcc) addMinute(r domain.Rollup) {
+	a.closeRun()
+	a.fromRollups = true
+	a.samples += r.Count
+	a.bad += r.LossCount
+	if a.slow(r.LatAvg) {
+		a.bad += r.Count - r.LossCount
+	}
+
+	follows := a.lastMinute.Add(time.Minute).Equal(r.Bucket)
+	a.lastMinute = r.Bucket
+	if !outageMinute(r) {
+		a.minuteOutage = false
+		return
+	}
+	if !a.minuteOutage || !follows {
+		a.outages++
+	}
+	a.minuteOutage = true
+	a.outageSamples += r.LossCount
+}
+
+func (a *slaAcc) report() domain.SLAReport {
+	a.closeRun()
+	rep := domain.SLAReport{
+		Definition: a.policy.Definition, Samples: a.samples, TargetPct: a.policy.TargetPct,
+		Outages: a.outages, Estimated: a.fromRollups,
+	}
+	if a.samples == 0 {
+		return rep
+	}
+
+	unavailable := a.bad
+	if a.policy.Definition == domain.SLAIncident {
+		unavailable = a.outageSamples
+	}
+	rep.Availability = float64(a.samples-unavailable) / float64(a.samples) * 100
+
+	step := sampleInterval.Seconds()
+	rep.DowntimeSec = float64(a.outageSamples) * step
+	if rep.Outages > 0 {
+		rep.MTTRSec = rep.DowntimeSec / float64(rep.Outages)
+		rep.MTBFSec = float64(a.samples-a.outageSamples) * step / float64(rep.Outages)
+	}
+	rep.MetTarget = a.policy.TargetPct <= 0 || rep.Availability >= a.policy.TargetPct
+	return rep
+}
+
+// outageMinute diz se um minuto agregado foi de queda: perdeu a maior parte dos pacotes,
+// e ao menos o bastante para abrir um incidente
+func outageMinute(r domain.Rollup) bool {
+	return r.LossCount >= incidentOpenAfter && r.LossCount*2 >= r.Count
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"lag-monitor/internal/domain"
+)
+
+// samples monta uma amostra por caractere: '.' responde em 10ms, 's' em 200ms e 'x' é perda
+func samples(pattern string) []domain.PingResult {
+	start := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+	out := make([]domain.PingResult, len(pattern))
+	for i, c := range pattern {
+		d := domain.PingResult{HostID: "h", Latency: 10_000, Timestamp: start.Add(time.Duration(i) * sampleInterval)}
+		switch c {
+		case 's':
+			d.Latency = 200_000
+		case 'x':
+			d.Latency, d.Loss = 0, true
+		}
+		out[i] = d
+	}
+	return out
+}
+
+func TestComputeSLA(t *testing.T) {
+	loss := domain.SLAPolicy{Definition: domain.SLALoss}
+	incident := domain.SLAPolicy{Definition: domain.SLAIncident}
+	latency := domain.SLAPolicy{Definition: domain.SLALatency, LatencyThresholdMs: 100}
+
+	tests := []struct {
+		name    string
+		pattern string
+		policy  domain.SLAPolicy
+		avail   float64
+		outages int
+		down    float64 // segundos
+		mtbf    float64
+		met     bool
+	}{
+		{"sem perdas", "..........", loss, 100, 0, 0, 0, true},
+		{"perdas curtas não são queda", "....xx....", loss, 80, 0, 0, 0, true},
+		{"queda", "..xxx.....", loss, 70, 1, 3, 7, true},
+		{"queda no fim do período", "......xxxx", loss, 60, 1, 4, 6, true},
+		{"duas quedas", "xxx..xxx..", loss, 40, 2, 6, 2, true},
+		{"política vazia usa perda", "..xxx.....", domain.SLAPolicy{}, 70, 1, 3, 7, true},
+		{"incidente ignora perdas avulsas", "x..xxxx...", incident, 60, 1, 4, 6, true},
+		{"latência conta respostas lentas", "..ss.x....", latency, 70, 0, 0, 0, true},
+		{"latência lenta seguida é queda", "..sss.....", latency, 70, 1, 3, 7, true},
+		{"latência sem limite", "ssss", domain.SLAPolicy{Definition: domain.SLALatency}, 100, 0, 0, 0, true},
+		{"meta não atingida", "x.........", domain.SLAPolicy{Definition: domain.SLALoss, TargetPct: 99}, 90, 0, 0, 0, false},
+		{"meta atingida", "x.........", domain.SLAPolicy{Definition: domain.SLALoss, TargetPct: 90}, 90, 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := computeSLA(samples(tt.pattern), tt.policy)
+			if rep.Samples != len(tt.pattern) {
+				t.Errorf("amostras = %d, esperado %d", rep.Samples, len(tt.pattern))
+			}
+			if math.Abs(rep.Availability-tt.avail) > 1e-9 {
+				t.Errorf("disponibilidade = %v, esperado %v", rep.Availability, tt.avail)
+			}
+			if rep.Outages != tt.outages {
+				t.Errorf("quedas = %d, esperado %d", rep.Outages, tt.outages)
+			}
+			if rep.DowntimeSec != tt.down {
+				t.Errorf("tempo fora = %v, esperado %v", rep.DowntimeSec, tt.down)
+			}
+			if rep.MTBFSec != tt.mtbf {
+				t.Errorf("MTBF = %v, esperado %v", rep.MTBFSec, tt.mtbf)
+			}
+			if tt.outages > 0 && rep.MTTRSec != tt.down/float64(tt.outages) {
+				t.Errorf("MTTR = %v, esperado %v", rep.MTTRSec, tt.down/float64(tt.outages))
+			}
+			if rep.MetTarget != tt.met {
+				t.Errorf("meta atingida = %v, esperado %v", rep.MetTarget, tt.met)
+			}
+			if rep.Estimated {
+				t.Error("SLA de amostras brutas marcado como estimado")
+			}
+		})
+	}
+}
+
+func TestComputeSLAEmpty(t *testing.T) {
+	rep := computeSLA(nil, domain.SLAPolicy{})
+	if rep.Samples != 0 || rep.Availability != 0 || rep.Outages != 0 {
+		t.Errorf("período vazio: %+v", rep)
+	}
+}
+
+func TestSLAFromMinutes(t *testing.T) {
+	start := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+	minute := func(i, count, lost int) domain.Rollup {
+		return domain.Rollup{Bucket: start.Add(time.Duration(i) * time.Minute), Count: count, LossCount: lost, LatAvg: 10_000}
+	}
+
+	acc := newSLAAcc(domain.SLAPolicy{Definition: domain.SLALoss})
+	for _, r := range []domain.Rollup{
+		minute(0, 60, 0),
+		minute(1, 60, 60), // Minutos seguidos de queda formam uma só
+		minute(2, 60, 40),
+		minute(3, 60, 2), // Perda baixa não é queda
+		minute(5, 60, 60),
+	} {
+		acc.addMinute(r)
+	}
+	rep := acc.report()
+
+	if !rep.Estimated {
+		t.Error("SLA dos agregados deveria ser estimado")
+	}
+	if rep.Samples != 300 || rep.Outages != 2 {
+		t.Errorf("amostras = %d, quedas = %d; esperado 300 e 2", rep.Samples, rep.Outages)
+	}
+	if want := float64(300-162) / 300 * 100; math.Abs(rep.Availability-want) > 1e-9 {
+		t.Errorf("disponibilidade = %v, esperado %v", rep.Availability, want)
+	}
+	if rep.DowntimeSec != 160 {
+		t.Errorf("tempo fora = %v, esperado 160", rep.DowntimeSec)
+	}
+}
+
+func TestValidateSLAPolicy(t *testing.T) {
+	tests := []struct {
+		policy domain.SLAPolicy
+		ok     bool
+	}{
+		{domain.SLAPolicy{}, true},
+		{domain.SLAPolicy{Definition: domain.SLALoss, TargetPct: 99.5}, true},
+		{domain.SLAPolicy{Definition: domain.SLAIncident}, true},
+		{domain.SLAPolicy{Definition: domain.SLALatency, LatencyThresholdMs: 150, TargetPct: 100}, true},
+		{domain.SLAPolicy{Definition: "uptime"}, false},
+		{domain.SLAPolicy{Definition: "Loss"}, false},
+		{domain.SLAPolicy{Definition: domain.SLALatency, LatencyThresholdMs: -1}, false},
+		{domain.SLAPolicy{Definition: domain.SLALoss, TargetPct: 101}, false},
+		{domain.SLAPolicy{Definition: domain.SLALoss, TargetPct: -5}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateSLAPolicy(tt.policy); (err == nil) != tt.ok {
+			t.Errorf("ValidateSLAPolicy(%+v) = %v, esperado válido = %v", tt.policy, err, tt.ok)
+		}
+	}
+
+	// GetSLA recusa a definição antes de ler qualquer dado
+	s := NewMonitorService(nil, nil, nil)
+	if _, err := s.GetSLA("h", time.Now().Add(-time.Hour), time.Now(), domain.SLAPolicy{Definition: "uptime"}, ReportOptions{}); err == nil {
+		t.Error("GetSLA aceitou uma definição desconhecida")
+	}
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"time"
+)
+
+// GetStats resume o período do host no nível adequado ao tamanho do período
+func (s *MonitorService) GetStats(hostID string, start, end time.Time) (domain.RangeStats, error) {
+	return s.repo.GetStats(hostID, s.pickResolution(start, end), start, end)
+}
+
+// GetSeries agrega o período do host em intervalos de tamanho bucket. Intervalos menores
+// que o nível disponível para o período são ampliados para o tamanho do nível.
+func (s *MonitorService) GetSeries(hostID string, start, end time.Time, bucket time.Duration) ([]domain.Rollup, error) {
+	res := s.pickResolution(start, end)
+	if bucket < res.Duration() {
+		bucket = res.Duration()
+	}
+	if bucket <= 0 {
+		bucket = time.Second
+	}
+	return s.repo.GetSeries(hostID, res, start, end, bucket)
+}
+
+// GetHistogram distribui as latências do período em faixas de binMs milissegundos.
+// Só existe enquanto as amostras brutas estão na retenção.
+func (s *MonitorService) GetHistogram(hostID string, start, end time.Time, binMs int64) ([]domain.HistogramBin, error) {
+	if s.pickResolution(start, start) != domain.ResolutionRaw {
+		return nil, fmt.Errorf("amostras brutas do período já removidas pela retenção")
+	}
+	if binMs <= 0 {
+		binMs = 10
+	}
+	return s.repo.GetHistogram(hostID, start, end, binMs*1000)
+}
+
+// accFromStats converte o resumo do banco no acumulador usado pelos grupos
+func accFromStats(st domain.RangeStats) statsAcc {
+	ok := st.Count - st.LossCount
+	return statsAcc{
+		samples: st.Count,
+		losses:  st.LossCount,
+		ok:      ok,
+		latSum:  st.LatAvg * int64(ok),
+		jitSum:  st.JitterAvg * int64(ok),
+		minLat:  st.LatMin,
+		maxLat:  st.LatMax,
+	}
+}
+
+// Quantidade de pontos buscada quando o gráfico não define o intervalo
+const defaultSeriesPoints = 720
+
+// GetHistorySeries retorna a série de cada host no período. bucket <= 0 escolhe um
+// intervalo que resulte em cerca de defaultSeriesPoints pontos.
+func (s *MonitorService) GetHistorySeries(hostIDs []string, start, end time.Time, bucket time.Duration) ([]domain.HostSeries, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("período inválido")
+	}
+	if bucket <= 0 {
+		bucket = (end.Sub(start) / defaultSeriesPoints).Round(time.Second)
+	}
+	// O mesmo intervalo para todos os hosts mantém as séries alinhadas
+	res := s.pickResolution(start, end)
+	if bucket < res.Duration() {
+		bucket = res.Duration()
+	}
+	if bucket < time.Second {
+		bucket = time.Second
+	}
+
+	out := make([]domain.HostSeries, 0, len(hostIDs))
+	for _, id := range hostIDs {
+		rollups, err := s.repo.GetSeries(id, res, start, end, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+
+		series := domain.HostSeries{
+			HostID:     id,
+			Resolution: res,
+			BucketSec:  int64(bucket / time.Second),
+			Points:     make([]domain.SeriesPoint, 0, len(rollups)),
+		}
+		for _, r := range rollups {
+			p := domain.SeriesPoint{
+				Time: r.Bucket, Count: r.Count,
+				LatMin: r.LatMin, LatAvg: r.LatAvg, LatMax: r.LatMax, JitterAvg: r.JitterAvg,
+			}
+			if r.Count > 0 {
+				p.LossRatio = float64(r.LossCount) / float64(r.Count)
+			}
+			series.Points = append(series.Points, p)
+		}
+		out = append(out, series)
+	}
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"fmt"
+	"lag-monitor/internal/domain"
+	"slices"
+	"sort"
+)
+
+// SetHostParent define de qual host o alvo depende. parentID vazio torna o host uma raiz.
+func (s *MonitorService) SetHostParent(id, parentID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.targets[id]
+	if !exists {
+		return fmt.Errorf("host %s não encontrado", id)
+	}
+
+	// Impede ciclos: o novo pai não pode depender (direta ou indiretamente) do host
+	for cur := parentID; cur != ""; {
+		if cur == id {
+			return fmt.Errorf("dependência circular entre %s e %s", id, parentID)
+		}
+		parent, ok := s.targets[cur]
+		if !ok {
+			return fmt.Errorf("host pai %s não encontrado", cur)
+		}
+		cur = parent.host.ParentID
+	}
+
+	job.host.ParentID = parentID
+	return nil
+}
+
+// GetTopology retorna o grafo de dependências com o estado atual de cada nó
+func (s *MonitorService) GetTopology() []domain.TopologyNode {
+	s.mu.RLock()
+	jobs := make([]*monitorJob, 0, len(s.targets))
+	nodes := make([]domain.TopologyNode, 0, len(s.targets))
+	for _, job := range s.targets {
+		h := job.host
+		h.Active = job.active
+		jobs = append(jobs, job)
+		nodes = append(nodes, domain.TopologyNode{Host: h, Status: domain.StatusUnknown})
+	}
+	s.mu.RUnlock()
+
+	for i, job := range jobs {
+		node := &nodes[i]
+
+		if last, ok := job.lastSample(); ok {
+			node.Latency = last.Latency
+			node.Status = domain.StatusUp
+			if last.Loss {
+				node.Status = domain.StatusDown
+			}
+		}
+		if !node.Active {
+			node.Status = domain.StatusPaused
+		}
+		node.Suppressed = node.Status == domain.StatusDown && s.ancestorDown(job)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// ancestorDown informa se algum host do qual o job depende está fora do ar
+func (s *MonitorService) ancestorDown(job *monitorJob) bool {
+	for _, anc := range s.chain(job)[1:] {
+		if anc.isDown() {
+			return true
+		}
+	}
+	return false
+}
+
+// isDown informa se o host tem incidente aberto ou perdeu a última amostra. Pai e filho
+// que começam a perder juntos abrem o incidente em momentos diferentes; a perda em curso
+// evita que o filho, chegando antes, seja tratado como queda própria.
+func (j *monitorJob) isDown() bool {
+	j.stateMu.Lock()
+	defer j.stateMu.Unlock()
+	return j.incident != nil || (len(j.recent) > 0 && j.recent[len(j.recent)-1].Loss)
+}
+
+// hasIncident informa se o host tem incidente aberto
+func (j *monitorJob) hasIncident() bool {
+	j.stateMu.Lock()
+	defer j.stateMu.Unlock()
+	return j.incident != nil
+}
+
+// suppressDescendants marca como suprimidos os incidentes abertos dos hosts que
+// dependem de job, abertos antes que a queda dele fosse conhecida
+func (s *MonitorService) suppressDescendants(job *monitorJob) {
+	s.mu.RLock()
+	jobs := make([]*monitorJob, 0, len(s.targets))
+	for _, j := range s.targets {
+		if j != job {
+			jobs = append(jobs, j)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, j := range jobs {
+		if !slices.Contains(s.chain(j)[1:], job) {
+			continue
+		}
+		j.stateMu.Lock()
+		inc := j.incident
+		if inc == nil || inc.Suppressed || inc.Planned {
+			j.stateMu.Unlock()
+			continue
+		}
+		inc.Suppressed = true
+		marked := *inc
+		j.stateMu.Unlock()
+
+		s.emit("incident:suppressed", marked)
+	}
+}
+
+func (j *monitorJob) lastSample() (domain.PingResult, bool) {
+	j.stateMu.Lock()
+	defer j.stateMu.Unlock()
+
+	if len(j.recent) == 0 {
+		return domain.PingResult{}, false
+	}
+	return j.recent[len(j.recent)-1], true
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"lag-monitor/internal/domain"
+)
+
+func TestSuppressionWhenParentAndChildFailTogether(t *testing.T) {
+	tests := []struct {
+		name string
+		// Ordem das perdas: "p" para o pai, "c" para o filho
+		order []string
+		want  []string // Eventos de incidente emitidos, em ordem
+	}{
+		{"pai abre primeiro", []string{"p", "c", "p", "c", "p", "c"},
+			[]string{"incident:open gw", "incident:suppressed pc"}},
+		{"filho chega antes com o pai já perdendo", []string{"p", "c", "c", "p", "c", "p"},
+			[]string{"incident:suppressed pc", "incident:open gw"}},
+		// O filho abre antes da primeira perda do pai: é suprimido quando o pai cai
+		{"filho antes do pai", []string{"c", "c", "c", "p", "p", "p"},
+			[]string{"incident:open pc", "incident:suppressed pc", "incident:open gw"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			s := NewMonitorService(nil, nil, func(name string, data interface{}) {
+				if inc, ok := data.(domain.Incident); ok {
+					events = append(events, name+" "+inc.HostID)
+				}
+			})
+			gw := &monitorJob{host: domain.Host{ID: "gw", IsGW: true}, active: true}
+			pc := &monitorJob{host: domain.Host{ID: "pc", ParentID: "gw"}, active: true}
+			s.targets["gw"], s.targets["pc"] = gw, pc
+
+			ts := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+			feed := func(job *monitorJob, loss bool) {
+				ts = ts.Add(time.Second / 2)
+				res := domain.PingResult{HostID: job.host.ID, Loss: loss, Timestamp: ts}
+				if !loss {
+					res.Latency = 10_000
+				}
+				job.record(res)
+				s.trackIncident(job, res)
+			}
+			for _, who := range tt.order {
+				if who == "p" {
+					feed(gw, true)
+				} else {
+					feed(pc, true)
+				}
+			}
+			if !slices.Equal(events, tt.want) {
+				t.Fatalf("eventos = %v, esperado %v", events, tt.want)
+			}
+
+			// Só fecha com incident:close quem foi anunciado como aberto
+			events = nil
+			for i := 0; i < incidentCloseAfter; i++ {
+				feed(gw, false)
+				feed(pc, false)
+			}
+			for _, ev := range events {
+				if ev == "incident:close pc" && tt.want[0] != "incident:open pc" {
+					t.Errorf("incidente suprimido desde a abertura emitiu %q", ev)
+				}
+			}
+			if tt.want[0] == "incident:open pc" && !slices.Contains(events, "incident:close pc") {
+				t.Errorf("incidente anunciado sem incident:close: %v", events)
+			}
+		})
+	}
+}
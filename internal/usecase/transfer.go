@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"lag-monitor/internal/domain"
+	"sort"
+	"time"
+)
+
+const (
+	// Período lido do repositório por vez na exportação (limita a memória usada)
+	exportChunk = 24 * time.Hour
+	// Amostras gravadas por transação na importação
+	importBatch = 5000
+)
+
+var errNoImport = errors.New("o armazenamento atual não suporta importação")
+
+// ExportHistory percorre as amostras brutas dos hosts no período, host a host e em
+// ordem cronológica, entregando cada uma a write. Retorna quantas foram exportadas.
+func (s *MonitorService) ExportHistory(hostIDs []string, start, end time.Time, write func(domain.PingResult) error) (int, error) {
+	total := 0
+	for _, hostID := range hostIDs {
+		err := forEachChunk(start, end, exportChunk, func(from, to time.Time) error {
+			data, err := s.repo.GetHistory(hostID, from, to)
+			if err != nil {
+				return err
+			}
+			for _, d := range data {
+				if err := write(d); err != nil {
+					return err
+				}
+			}
+			total += len(data)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ExportRollups faz o mesmo com os agregados de um nível (1m ou 1h)
+func (s *MonitorService) ExportRollups(hostIDs []string, res domain.Resolution, start, end time.Time, write func(domain.Rollup) error) (int, error) {
+	if res.Duration() <= 0 {
+		return 0, fmt.Errorf("resolução %q não possui agregados", res)
+	}
+	// Blocos com o mesmo número de intervalos que um dia de amostras por segundo
+	chunk := exportChunk * (res.Duration() / time.Second)
+
+	total := 0
+	for _, hostID := range hostIDs {
+		err := forEachChunk(domain.BucketStart(start, res.Duration()), end, chunk, func(from, to time.Time) error {
+			rollups, err := s.repo.GetRollups(hostID, res, from, to)
+			if err != nil {
+				return err
+			}
+			for _, r := range rollups {
+				if err := write(r); err != nil {
+					return err
+				}
+			}
+			total += len(rollups)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// forEachChunk divide [start, end] em blocos consecutivos. As consultas incluem os
+// dois extremos: cada bloco termina 1µs antes do próximo.
+func forEachChunk(start, end time.Time, chunk time.Duration, fn func(from, to time.Time) error) error {
+	for from := start; !from.After(end); from = from.Add(chunk) {
+		to := from.Add(chunk - time.Microsecond)
+		if to.After(end) {
+			to = end
+		}
+		if err := fn(from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportHistory grava no repositório as amostras entregues por decode, em lotes,
+// ignorando as que já existem (precision é a resolução dos timestamps da origem).
+// decode retorna quantas linhas não puderam ser interpretadas.
+func (s *MonitorService) ImportHistory(format string, precision time.Duration, decode func(fn func(domain.PingResult) error) (int, error)) (domain.ImportReport, error) {
+	report := domain.ImportReport{Format: format, HostIDs: []string{}}
+	importer, ok := s.repo.(domain.Importer)
+	if !ok {
+		return report, errNoImport
+	}
+
+	began := time.Now()
+	hosts := map[string]bool{}
+	batch := make([]domain.PingResult, 0, importBatch)
+
+	save := func() error {
+		n, err := importer.Import(batch, precision)
+		if err != nil {
+			return err
+		}
+		report.Imported += n
+		report.Duplicates += len(batch) - n
+		batch = batch[:0]
+		return nil
+	}
+
+	invalid, err := decode(func(d domain.PingResult) error {
+		report.Read++
+		hosts[d.HostID] = true
+		batch = append(batch, d)
+		if len(batch) == importBatch {
+			return save()
+		}
+		return nil
+	})
+	if err == nil {
+		err = save()
+	}
+
+	report.Invalid = invalid
+	for h := range hosts {
+		report.HostIDs = append(report.HostIDs, h)
+	}
+	sort.Strings(report.HostIDs)
+	report.DurationMs = time.Since(began).Milliseconds()
+
+	if err != nil {
+		return report, err
+	}
+	s.emit("history:imported", report)
+	return report, nil
+}
@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"fmt"
 	"lag-monitor/internal/config" // Importe o novo pacote config
+	"lag-monitor/internal/domain"
 	"lag-monitor/internal/infra/database"
+	"lag-monitor/internal/infra/hooks"
+	"lag-monitor/internal/infra/memory"
 	"lag-monitor/internal/infra/network"
+	"lag-monitor/internal/infra/segment"
 	"lag-monitor/internal/usecase"
 	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -17,17 +27,24 @@ import (
 var assets embed.FS
 
 func main() {
-	// 1. Infra - Banco de Dados
-	dbPath := database.GetDatabasePath()
-	repo, err := database.NewSQLiteRepo(dbPath)
+	// 1. Infra - Configuração
+	// Carregada antes do armazenamento, que é escolhido no settings.json
+	cfg := config.NewConfigManager()
+	if err := cfg.Load(); err != nil {
+		log.Println("Erro ao carregar config:", err)
+	}
+
+	// Subcomandos (backup, snapshots, restore) rodam sem abrir a janela
+	if handled, code := runCLI(os.Args[1:], cfg.Data.Storage); handled {
+		os.Exit(code)
+	}
+
+	// 2. Infra - Armazenamento
+	repo, err := openRepository(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// 2. Infra - Configuração (NOVO)
-	// Instanciamos o gerenciador de configuração aqui
-	cfg := config.NewConfigManager()
-
 	// 3. Infra - Rede
 	pinger := network.NewPinger()
 
@@ -35,26 +52,64 @@ func main() {
 	// Usamos uma variável declarada antes para o closure do emitter capturar o contexto do App
 	var app *App
 
+	// Hooks do usuário (settings.json) são disparados a partir dos mesmos eventos do frontend
+	hookRunner := hooks.NewRunner(cfg.GetHooks)
+
 	emitter := func(event string, data interface{}) {
 		// Verifica se o app e o contexto já foram inicializados
 		if app != nil && app.ctx != nil {
 			runtime.EventsEmit(app.ctx, event, data)
 		}
+		hookRunner.Dispatch(event, data)
+	}
+
+	// Falhas de gravação e descartes do buffer chegam ao frontend (e aos hooks)
+	if n, ok := repo.(domain.ErrorNotifier); ok {
+		n.OnError(func(e domain.StorageError) {
+			log.Printf("armazenamento (%s): %s", e.Op, e.Message)
+			emitter("storage:error", e)
+		})
 	}
 
+	hookRunner.OnResult(func(res hooks.Result) {
+		switch {
+		case res.Skipped:
+			log.Printf("hook %q (%s) não executado: %s", res.Command, res.Event, res.Error)
+		case res.Error != "":
+			log.Printf("hook %q (%s) falhou: %s", res.Command, res.Event, res.Error)
+		}
+		emitter("hook:result", res)
+	})
+
 	service := usecase.NewMonitorService(repo, pinger, emitter)
+	// Mudanças na rede local viram anotações na linha do tempo
+	service.SetNetworkProbe(network.NewInterfaceProbe())
 
 	// 5. Inicialização do App (ATUALIZADO)
 	// Agora passamos o repo e o cfg para dentro do App
 	app = NewApp(service, repo, cfg)
 
+	// SIGINT/SIGTERM encerram pelo mesmo caminho do fechamento da janela
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		if app.ctx != nil {
+			runtime.Quit(app.ctx)
+			return
+		}
+		app.shutdown(context.Background())
+		os.Exit(0)
+	}()
+
 	// 6. Wails Run
 	err = wails.Run(&options.App{
-		Title:     "LAGMON",
-		Width:     1024, // Ajustei para um tamanho inicial mais confortável para o Dashboard
-		Height:    768,
-		Assets:    assets,
-		OnStartup: app.startup, // O app.startup agora vai chamar cfg.Load()
+		Title:      "LAGMON",
+		Width:      1024, // Ajustei para um tamanho inicial mais confortável para o Dashboard
+		Height:     768,
+		Assets:     assets,
+		OnStartup:  app.startup,
+		OnShutdown: app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -63,4 +118,42 @@ func main() {
 	if err != nil {
 		println("Error:", err.Error())
 	}
+
+	// Garante o flush mesmo se o Wails terminar sem chamar OnShutdown
+	app.shutdown(context.Background())
+}
+
+// openRepository cria o armazenamento escolhido no settings.json
+func openRepository(cfg *config.ConfigManager) (domain.Repository, error) {
+	sc := cfg.Data.Storage
+	switch sc.Backend {
+	case "", config.BackendSQLite:
+		path := sc.Path
+		if path == "" {
+			path = database.GetDatabasePath()
+		}
+		repo, err := database.NewSQLiteRepo(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.ImportLegacySettings(repo.LegacySettings()); err != nil {
+			log.Println("Falha ao migrar preferências do banco:", err)
+		}
+		return repo, nil
+
+	case config.BackendSegment:
+		dir := sc.Path
+		if dir == "" {
+			dir = filepath.Join(filepath.Dir(database.GetDatabasePath()), "segments")
+		}
+		store, err := segment.NewStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+
+	case config.BackendMemory:
+		return memory.NewStore(sc.MemorySamples), nil
+	}
+	return nil, fmt.Errorf("backend de armazenamento desconhecido: %q", sc.Backend)
 }